@@ -9,6 +9,8 @@ import (
 	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"net/url"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -23,6 +25,71 @@ var sslModes = map[string]bool{
 	"verify-full": true,
 }
 
+// searchQualifiers corresponds to the GitHub search API qualifiers that can be
+// used to restrict the repositories to crawl.
+// The "language" qualifier is not part of it since it is set by the crawler
+// from the languages of interest.
+// See https://docs.github.com/en/search-github/searching-on-github/searching-for-repositories
+// for details.
+var searchQualifiers = map[string]bool{
+	"archived":           true,
+	"created":            true,
+	"followers":          true,
+	"fork":               true,
+	"forks":              true,
+	"good-first-issues":  true,
+	"help-wanted-issues": true,
+	"in":                 true,
+	"is":                 true,
+	"license":            true,
+	"mirror":             true,
+	"org":                true,
+	"pushed":             true,
+	"repo":               true,
+	"size":               true,
+	"stars":              true,
+	"template":           true,
+	"topic":              true,
+	"topics":             true,
+	"user":               true,
+}
+
+// searchRangeQualifiers maps the GitHub search API qualifiers whose value is
+// a number or a date, or a range of them (eg: ">100", "10..50", "*..2015-01-01"),
+// to the pattern of their bounds.
+var searchRangeQualifiers = map[string]*regexp.Regexp{
+	"created":            searchDatePattern,
+	"followers":          searchNumberPattern,
+	"forks":              searchNumberPattern,
+	"good-first-issues":  searchNumberPattern,
+	"help-wanted-issues": searchNumberPattern,
+	"pushed":             searchDatePattern,
+	"size":               searchNumberPattern,
+	"stars":              searchNumberPattern,
+	"topics":             searchNumberPattern,
+}
+
+var (
+	searchNumberPattern = regexp.MustCompile(`^[0-9]+$`)
+	searchDatePattern   = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}(T[0-9]{2}:[0-9]{2}(:[0-9]{2})?(Z|[+-][0-9]{2}:[0-9]{2})?)?$`)
+)
+
+// searchSorts corresponds to the sort fields of the GitHub search API.
+var searchSorts = map[string]bool{
+	"stars":              true,
+	"forks":              true,
+	"help-wanted-issues": true,
+	"updated":            true,
+}
+
+// gitBackends are the valid values of the git_backend option.
+var gitBackends = map[string]bool{
+	"":        true,
+	"libgit2": true,
+	"go-git":  true,
+	"cli":     true,
+}
+
 // Config is the main configuration structure.
 type Config struct {
 	// CloneDir is the path to the folder where all repositories are cloned.
@@ -59,6 +126,46 @@ type Config struct {
 	// independently of the language.
 	FetchLanguages []string `json:"fetch_languages"`
 
+	// FetchLanguagesThreshold is the minimum share, in percent, of the code
+	// of a repository that must be written in one of FetchLanguages for the
+	// repository to be fetched, as known from the repository_languages table.
+	// If 0 (the default), only the primary language of repositories is
+	// matched against FetchLanguages.
+	FetchLanguagesThreshold float64 `json:"fetch_languages_threshold"`
+
+	// SVNPath is the path to check out from subversion repositories, relative
+	// to their clone URL. It defaults to "trunk". "." checks out the clone
+	// URL itself.
+	SVNPath string `json:"svn_path"`
+
+	// GitBackend is the implementation used to clone and update git
+	// repositories: "libgit2" (the default), "go-git", a pure Go
+	// implementation, or "cli", which uses the git command. go-git is the
+	// default when crawld is built with the nolibgit2 tag, in which case
+	// libgit2 is not available.
+	GitBackend string `json:"git_backend"`
+
+	// VCSTimeout is the maximum duration of the commands run to clone or
+	// update a repository, such as "30m". Commands are not limited if empty.
+	// It only applies to mercurial and subversion repositories, and to git
+	// repositories when GitBackend is "cli".
+	VCSTimeout string `json:"vcs_timeout"`
+
+	// CloneDepth is the number of commits to fetch when cloning and updating
+	// git repositories. The whole history is fetched if 0 (the default). It
+	// requires GitBackend to be "go-git" or "cli".
+	CloneDepth int `json:"clone_depth"`
+
+	// SingleBranch specifies whether only the default branch of git
+	// repositories shall be fetched. It requires GitBackend to be "go-git" or
+	// "cli".
+	SingleBranch bool `json:"single_branch"`
+
+	// CloneFilter is the partial clone filter of git repositories, such as
+	// "blob:none", which fetches the contents of the files of the checked
+	// out tree only. It requires GitBackend to be "cli".
+	CloneFilter string `json:"clone_filter"`
+
 	// ThrottlerWaitTime can be used to specify how much time to wait, in
 	// seconds, before resuming normal operations if the error rate is too high
 	// (defaults to 1800).
@@ -85,9 +192,22 @@ type Config struct {
 
 // CrawlerConfig is a configuration for a crawler.
 type CrawlerConfig struct {
-	// Type defines the crawler type (eg: "github").
+	// Type defines the crawler type (eg: "github", "gitlab").
 	Type string `json:"type"`
 
+	// Name identifies the crawler, for instance to persist its progress. It
+	// defaults to the crawler type and must be unique, which means that it
+	// must be set when several crawlers of the same type are configured.
+	Name string `json:"name"`
+
+	// BaseURL is the URL of the platform instance to crawl. This is
+	// useful for platforms that can be self-hosted, such as GitLab. When
+	// left empty, the crawler uses the URL of the public instance of the
+	// platform (eg: "https://gitlab.com/" for the gitlab crawler).
+	// It is mandatory for the gitea crawler (also available as "gogs" and
+	// "forgejo") since these platforms have no public instance.
+	BaseURL string `json:"base_url"`
+
 	// Languages is the list of programming languages of interest.
 	Languages []string `json:"languages"`
 
@@ -103,11 +223,20 @@ type CrawlerConfig struct {
 	// SinceID corresponds to the repository ID (eg: GitHub repository ID in
 	// the case of the github crawler) from which to start querying repositories.
 	// Note that this value is ignored when using the search API.
+	// The github and gitea crawlers persist the ID of the last repository
+	// they processed and resume from it, unless since_id is greater.
 	SinceID int `json:"since_id"`
 
 	// Fork indicate whether "fork" repositories need to be crawled or not.
 	Fork bool `json:"fork"`
 
+	// ForkDepth is the depth down to which the fork tree of each crawled
+	// repository is crawled: 1 crawls the forks of the repository, 2 also
+	// crawls the forks of the forks, and so on. Forks crawled that way do not
+	// count against the limit. It defaults to 0, which does not crawl forks.
+	// This option is only supported by the github crawler.
+	ForkDepth int `json:"fork_depth"`
+
 	// OAuthAccessToken is the API token. If not provided, crawld will work but
 	// the number of API call is usually limited to a low number.
 	// For instance, in the case of the GitHub crawler, unauthenticated
@@ -115,6 +244,12 @@ type CrawlerConfig struct {
 	// to 5000 per hour.
 	OAuthAccessToken string `json:"oauth_access_token"`
 
+	// OAuthAccessTokens is a list of additional API tokens. It is only
+	// supported by the github crawler. Each request is made with the token
+	// that has the most API calls left and the crawler only waits for the
+	// rate limit to be reset when all tokens are exhausted.
+	OAuthAccessTokens []string `json:"oauth_access_tokens"`
+
 	// UseSearchAPI specifies whether to use the search API or not. The number
 	// of results returned by a search API is usually limited. For instance,
 	// the GitHub search API limits the results to 1000 repositories.
@@ -124,6 +259,97 @@ type CrawlerConfig struct {
 	// stars). When a lot of data is wanted, this option shall therefore be set
 	// to false.
 	UseSearchAPI bool `json:"use_search_api"`
+
+	// SearchDateSlicing specifies whether search queries shall be split by
+	// ranges of repositories creation dates until each query has less results
+	// than the search API gives access to. This way, all the repositories of
+	// a language can be crawled with the search API. It requires UseSearchAPI
+	// and is only supported by the github crawler.
+	SearchDateSlicing bool `json:"search_date_slicing"`
+
+	// SearchStarsSlicing specifies whether search queries that still have too
+	// many results when restricted to a single creation day shall be further
+	// split by ranges of stars. It requires SearchDateSlicing.
+	SearchStarsSlicing bool `json:"search_stars_slicing"`
+
+	// SearchQualifiers is a list of additional GitHub search qualifiers
+	// (eg: "stars:>100", "pushed:>2015-01-01" or "license:mit") restricting
+	// the repositories to crawl when using the search API.
+	SearchQualifiers []string `json:"search_qualifiers"`
+
+	// SearchSort is the field by which search results are sorted, one of
+	// "stars" (default), "forks", "help-wanted-issues" or "updated".
+	SearchSort string `json:"search_sort"`
+
+	// SearchOrder is the order of search results, either "desc" (default) or
+	// "asc".
+	SearchOrder string `json:"search_order"`
+
+	// CrawlContributors specifies whether the contributors of each
+	// repository shall be crawled, along with their number of contributions.
+	// Otherwise, only the owner of a repository, or the members of the
+	// organization owning it, are linked to the repository.
+	// This option is only supported by the github crawler.
+	CrawlContributors bool `json:"crawl_contributors"`
+
+	// CrawlStargazers specifies whether the stargazers of each repository
+	// shall be crawled, along with the time at which they starred it. Only
+	// the new stargazers are fetched on re-crawls.
+	// This option is only supported by the github crawler.
+	CrawlStargazers bool `json:"crawl_stargazers"`
+
+	// CrawlWatchers specifies whether the watchers of each repository shall
+	// be crawled. They are only fetched again when their number changes.
+	// This option is only supported by the github crawler.
+	CrawlWatchers bool `json:"crawl_watchers"`
+
+	// CrawlIssues specifies whether the issues and pull requests of each
+	// repository shall be crawled. Only those updated since the last crawl
+	// are fetched on re-crawls.
+	// This option is only supported by the github crawler.
+	CrawlIssues bool `json:"crawl_issues"`
+
+	// CrawlReleases specifies whether the releases of each repository, along
+	// with their assets, and its tags shall be crawled.
+	// This option is only supported by the github crawler.
+	CrawlReleases bool `json:"crawl_releases"`
+
+	// SnapshotStats specifies whether a snapshot of the counts of each
+	// repository and user, such as their numbers of stars or followers,
+	// shall be appended to the stats tables on each crawl, so that their
+	// history is kept. No snapshot is taken when the counts have not changed.
+	// This option is only supported by the github crawler.
+	SnapshotStats bool `json:"snapshot_stats"`
+
+	// RevalidateRepos specifies whether the repositories already in the
+	// database shall be fetched again at the beginning of each crawl.
+	// Repositories that have been deleted are then marked as gone, so that
+	// they are not fetched anymore, and renamed or transferred ones are
+	// updated, so that their clones are moved.
+	// This option is only supported by the github crawler.
+	RevalidateRepos bool `json:"revalidate_repos"`
+
+	// UseETags specifies whether the ETag and Last-Modified values of the
+	// fetched repositories, users and organizations shall be stored in the
+	// database and used to make conditional requests. Resources that have not
+	// been modified since the last crawl are then not updated, and the
+	// corresponding requests do not count against the API rate limit.
+	// This option is only supported by the github crawler.
+	UseETags bool `json:"use_etags"`
+
+	// UseGraphQL specifies whether repositories shall be fetched with the
+	// GraphQL API, which fetches a batch of repositories along with their
+	// owner, their languages and the members of the organization owning them
+	// in a single query, rather than with several calls to the REST API for
+	// each repository.
+	// This option is only supported by the github crawler.
+	UseGraphQL bool `json:"use_graphql"`
+
+	// SeedFile is the path to the file listing the repositories to register
+	// when using the seedlist crawler. Each line of the file is either a
+	// clone URL, a CSV record (clone URL, name, language, owner, VCS) or a
+	// JSON object with the same fields. It is ignored by other crawlers.
+	SeedFile string `json:"seed_file"`
 }
 
 // DatabaseConfig is a configuration for PostgreSQL database connection
@@ -183,6 +409,12 @@ func ReadConfig(path string) (*Config, error) {
 		cfg.LeakInterval = 1000
 	}
 
+	for i, cc := range cfg.Crawlers {
+		if len(strings.Trim(cc.Name, " ")) == 0 {
+			cfg.Crawlers[i].Name = cc.Type
+		}
+	}
+
 	if err := cfg.verify(); err != nil {
 		return nil, err
 	}
@@ -207,6 +439,37 @@ func (c Config) verify() error {
 		return errors.New("config: max_fetcher_workers needs to be at least 1")
 	}
 
+	if c.FetchLanguagesThreshold < 0 || c.FetchLanguagesThreshold > 100 {
+		return errors.New("config: fetch_languages_threshold must be between 0 and 100")
+	}
+
+	if _, ok := gitBackends[c.GitBackend]; !ok {
+		return errors.New("config: invalid git_backend: " + c.GitBackend)
+	}
+
+	if c.VCSTimeout != "" {
+		if d, err := time.ParseDuration(c.VCSTimeout); err != nil || d <= 0 {
+			return errors.New("config: vcs_timeout must be a positive duration")
+		}
+	}
+
+	if c.CloneDepth < 0 {
+		return errors.New("config: clone_depth cannot be negative")
+	}
+
+	gitBackend := c.GitBackend
+	if gitBackend == "" {
+		gitBackend = defaultGitBackend
+	}
+
+	if (c.CloneDepth > 0 || c.SingleBranch) && gitBackend == "libgit2" {
+		return errors.New("config: clone_depth and single_branch require git_backend to be go-git or cli")
+	}
+
+	if c.CloneFilter != "" && gitBackend != "cli" {
+		return errors.New("config: clone_filter requires git_backend to be cli")
+	}
+
 	if c.ThrottlerWaitTime == 0 {
 		return errors.New("config: throttler_wait_time must be positive")
 	}
@@ -219,10 +482,16 @@ func (c Config) verify() error {
 		return errors.New("config: throttler_leak_interval must be >= 100")
 	}
 
+	names := map[string]bool{}
 	for _, cs := range c.Crawlers {
 		if err := cs.verify(); err != nil {
 			return err
 		}
+
+		if names[cs.Name] {
+			return errors.New("config: duplicated crawler name " + cs.Name)
+		}
+		names[cs.Name] = true
 	}
 
 	if err := c.Database.verify(); err != nil {
@@ -237,7 +506,13 @@ func (cc CrawlerConfig) verify() error {
 		return errors.New("config: crawler type cannot be empty")
 	}
 
-	if len(cc.Languages) == 0 {
+	// a seed list does not necessarily tell the language of its entries so
+	// the languages are only used as a filter
+	if cc.Type == "seedlist" {
+		if len(strings.Trim(cc.SeedFile, " ")) == 0 {
+			return errors.New("config: seedlist crawler requires a seed file")
+		}
+	} else if len(cc.Languages) == 0 {
 		return errors.New("config: crawler must have at least one language")
 	}
 
@@ -245,6 +520,62 @@ func (cc CrawlerConfig) verify() error {
 		return errors.New("config: crawler since id must be >= 0")
 	}
 
+	if cc.ForkDepth < 0 {
+		return errors.New("config: crawler fork depth must be >= 0")
+	}
+
+	if cc.SearchDateSlicing && !cc.UseSearchAPI {
+		return errors.New("config: search date slicing requires the search API")
+	}
+
+	if cc.SearchStarsSlicing && !cc.SearchDateSlicing {
+		return errors.New("config: search stars slicing requires search date slicing")
+	}
+
+	if (len(cc.SearchQualifiers) != 0 || len(cc.SearchSort) != 0 || len(cc.SearchOrder) != 0) && !cc.UseSearchAPI {
+		return errors.New("config: search qualifiers, sort and order require the search API")
+	}
+
+	for _, q := range cc.SearchQualifiers {
+		if err := verifySearchQualifier(q); err != nil {
+			return err
+		}
+
+		switch key := q[:strings.Index(q, ":")]; {
+		case key == "created" && cc.SearchDateSlicing:
+			return errors.New("config: search qualifier " + q + " conflicts with search date slicing")
+		case key == "stars" && cc.SearchStarsSlicing:
+			return errors.New("config: search qualifier " + q + " conflicts with search stars slicing")
+		}
+	}
+
+	// resources fetched with the GraphQL API have no ETag
+	if cc.UseGraphQL && cc.UseETags {
+		return errors.New("config: use_etags cannot be used along with use_graphql")
+	}
+
+	if len(cc.SearchSort) != 0 && !searchSorts[cc.SearchSort] {
+		return errors.New("config: invalid search sort: " + cc.SearchSort)
+	}
+
+	if cc.SearchOrder != "" && cc.SearchOrder != "asc" && cc.SearchOrder != "desc" {
+		return errors.New("config: search order must be either asc or desc")
+	}
+
+	// stars slicing relies on results being sorted by decreasing stars
+	if cc.SearchStarsSlicing && ((cc.SearchSort != "" && cc.SearchSort != "stars") || cc.SearchOrder == "asc") {
+		return errors.New("config: search stars slicing requires results sorted by decreasing stars")
+	}
+
+	if len(strings.Trim(cc.BaseURL, " ")) != 0 {
+		u, err := url.Parse(cc.BaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return errors.New("config: crawler base url must be an absolute http(s) URL")
+		}
+	} else if cc.Type == "gitea" || cc.Type == "gogs" || cc.Type == "forgejo" {
+		return errors.New("config: " + cc.Type + " crawler requires a base url")
+	}
+
 	return nil
 }
 
@@ -271,3 +602,49 @@ func (dc DatabaseConfig) verify() error {
 
 	return nil
 }
+
+// verifySearchQualifier checks that q is a valid GitHub search qualifier, ie
+// a known qualifier followed by a colon and a value (eg: "stars:>100").
+func verifySearchQualifier(q string) error {
+	i := strings.Index(q, ":")
+	if i <= 0 || i == len(q)-1 {
+		return errors.New("config: invalid search qualifier " + q + " (expected qualifier:value)")
+	}
+
+	if key := q[:i]; key == "language" {
+		return errors.New("config: search qualifier " + q + " conflicts with the crawler languages")
+	} else if !searchQualifiers[key] {
+		return errors.New("config: unknown search qualifier " + key)
+	}
+
+	if strings.ContainsAny(q, " \t\n") {
+		return errors.New("config: search qualifier " + q + " cannot contain whitespaces")
+	}
+
+	if bound, ok := searchRangeQualifiers[q[:i]]; ok && !isSearchRange(q[i+1:], bound) {
+		return errors.New("config: invalid range in search qualifier " + q)
+	}
+
+	return nil
+}
+
+// isSearchRange tells whether v is a valid value of a GitHub search range
+// qualifier, ie a bound, a bound preceded by a comparison operator or two
+// bounds separated by "..", one of which may be "*".
+func isSearchRange(v string, bound *regexp.Regexp) bool {
+	if i := strings.Index(v, ".."); i >= 0 {
+		low, high := v[:i], v[i+2:]
+		if low == "*" && high == "*" {
+			return false
+		}
+		return (low == "*" || bound.MatchString(low)) && (high == "*" || bound.MatchString(high))
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if strings.HasPrefix(v, op) {
+			v = v[len(op):]
+			break
+		}
+	}
+	return bound.MatchString(v)
+}
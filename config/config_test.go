@@ -20,6 +20,7 @@ const (
 
 	expectedCrawlersLen             = 1
 	expectedCrawlerType             = "github"
+	expectedCrawlerName             = "github"
 	expectedCrawlerLanguages        = "go,ruby"
 	expectedCrawlerLimit            = 0
 	expectedCrawlerSinceID          = 42
@@ -65,6 +66,11 @@ func TestReadConfig(t *testing.T) {
 			expectedCrawlerType, cfg.Crawlers[0].Type)
 	}
 
+	if cfg.Crawlers[0].Name != expectedCrawlerName {
+		t.Errorf("crawlers[0].name: expected '%s', found '%s'\n",
+			expectedCrawlerName, cfg.Crawlers[0].Name)
+	}
+
 	if strings.Join(cfg.Crawlers[0].Languages, ",") != expectedCrawlerLanguages {
 		t.Errorf("crawlers[0].languages: expected '%s', found '%s'\n",
 			expectedCrawlerLanguages, strings.Join(cfg.Crawlers[0].Languages, ","))
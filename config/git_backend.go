@@ -0,0 +1,11 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !nolibgit2
+// +build !nolibgit2
+
+package config
+
+// defaultGitBackend is the git backend in use when git_backend is empty.
+const defaultGitBackend = "libgit2"
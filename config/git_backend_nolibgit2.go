@@ -0,0 +1,12 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build nolibgit2
+// +build nolibgit2
+
+package config
+
+// defaultGitBackend is the git backend in use when git_backend is empty,
+// libgit2 not being available when building with the nolibgit2 tag.
+const defaultGitBackend = "go-git"
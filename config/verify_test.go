@@ -0,0 +1,51 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package config
+
+import "testing"
+
+func TestCrawlerConfigVerifySearch(t *testing.T) {
+	tests := []struct {
+		name       string
+		qualifiers []string
+		sort       string
+		order      string
+		valid      bool
+	}{
+		{name: "stars", qualifiers: []string{"stars:>100"}, valid: true},
+		{name: "stars range", qualifiers: []string{"stars:10..50"}, valid: true},
+		{name: "pushed", qualifiers: []string{"pushed:>2024-01-01"}, valid: true},
+		{name: "pushed open range", qualifiers: []string{"pushed:2024-01-01..*"}, valid: true},
+		{name: "topic", qualifiers: []string{"topic:compiler"}, valid: true},
+		{name: "license", qualifiers: []string{"license:mit"}, valid: true},
+		{name: "sort and order", sort: "forks", order: "asc", valid: true},
+		{name: "unknown qualifier", qualifiers: []string{"color:blue"}},
+		{name: "language qualifier", qualifiers: []string{"language:go"}},
+		{name: "missing value", qualifiers: []string{"license:"}},
+		{name: "malformed range", qualifiers: []string{"stars:10..high"}},
+		{name: "malformed date", qualifiers: []string{"pushed:>yesterday"}},
+		{name: "unbounded range", qualifiers: []string{"stars:*..*"}},
+		{name: "invalid sort", sort: "name"},
+		{name: "invalid order", order: "up"},
+	}
+
+	for _, tt := range tests {
+		cc := CrawlerConfig{
+			Type:             "github",
+			Languages:        []string{"go"},
+			UseSearchAPI:     true,
+			SearchQualifiers: tt.qualifiers,
+			SearchSort:       tt.sort,
+			SearchOrder:      tt.order,
+		}
+
+		err := cc.verify()
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected a valid configuration, found %v", tt.name, err)
+		} else if !tt.valid && err == nil {
+			t.Errorf("%s: expected an invalid configuration", tt.name)
+		}
+	}
+}
@@ -36,6 +36,10 @@ const version = "1.0.0"
 type dbRepo struct {
 	repo.Repo
 	id uint64
+
+	// previousPath is the absolute path to the repository on disk before it
+	// was renamed or transferred, if its clone has not been moved yet.
+	previousPath string
 }
 
 // channel used to communicate repositories IDs
@@ -89,8 +93,8 @@ func repoWorker(db *sql.DB, cfg *config.Config, startID uint64, errBag *errbag.E
 			glog.Warningf("impossible to update %s ("+err.Error()+")", r.AbsPath())
 			errBag.Record(err, callback)
 
-			// we just want to skip on a network error
-			if err == repo.ErrNetwork {
+			// we just want to skip on a network error or a timeout
+			if err == repo.ErrNetwork || err == repo.ErrTimeout {
 				return err
 			}
 
@@ -108,7 +112,7 @@ func repoWorker(db *sql.DB, cfg *config.Config, startID uint64, errBag *errbag.E
 
 	for {
 		glog.Info("starting the repositories fetcher")
-		repos, err := getAllRepos(db, startID, cfg.FetchLanguages, cfg.CloneDir)
+		repos, err := getAllRepos(db, startID, cfg.FetchLanguages, cfg.FetchLanguagesThreshold, cfg.CloneDir, repoOptions(cfg))
 		if err != nil {
 			fatal(err)
 		}
@@ -138,6 +142,10 @@ func repoWorker(db *sql.DB, cfg *config.Config, startID uint64, errBag *errbag.E
 							}
 						}()
 
+						if r.previousPath != "" {
+							moveClone(db, r)
+						}
+
 						var tmpPath, tmpDest string
 						var useTmpDir bool
 						archive := r.AbsPath() + ".tar"
@@ -262,17 +270,98 @@ func isDirEmpty(path string) bool {
 	return len(fis) == 0
 }
 
-func getAllRepos(db *sql.DB, startID uint64, langs []string, basePath string) ([]dbRepo, error) {
-	inClause := fmt.Sprintf("WHERE id >= %d", startID)
+// moveClone moves the clone of a repository, or its tar archive, from the
+// path of the repository before it was renamed or transferred to its current
+// path. The previous path is kept in the database if the clone cannot be
+// moved, so that it is attempted again on the next fetch.
+func moveClone(db *sql.DB, r dbRepo) {
+	if !moveCloneFiles(r.previousPath, r.AbsPath()) {
+		return
+	}
+
+	if _, err := db.Exec("UPDATE repositories SET previous_clone_path = NULL WHERE id=$1", r.id); err != nil {
+		glog.Error(err)
+	}
+}
+
+// moveCloneFiles moves the clone directory, and the tar archive, found at
+// prevPath to path. It returns false if any of them exists but could not be
+// moved, for instance because its destination already exists.
+func moveCloneFiles(prevPath, path string) bool {
+	if prevPath == path {
+		// the repository has been renamed back
+		return true
+	}
+
+	moved := true
+	for _, ext := range []string{"", ".tar"} {
+		src, dest := prevPath+ext, path+ext
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if _, err := os.Stat(dest); err == nil {
+			glog.Warningf("cannot move %s to %s: destination already exists", src, dest)
+			moved = false
+			continue
+		}
+
+		glog.Infof("moving %s to %s", src, dest)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			glog.Error(err)
+			return false
+		}
+		if err := os.Rename(src, dest); err != nil {
+			glog.Error(err)
+			return false
+		}
+	}
+
+	return moved
+}
+
+// repoOptions returns the options of the repositories to fetch.
+func repoOptions(cfg *config.Config) repo.Options {
+	// the timeout has been checked when reading the configuration
+	timeout, _ := time.ParseDuration(cfg.VCSTimeout)
+
+	return repo.Options{
+		GitBackend:   cfg.GitBackend,
+		Timeout:      timeout,
+		CloneDepth:   cfg.CloneDepth,
+		SingleBranch: cfg.SingleBranch,
+		CloneFilter:  cfg.CloneFilter,
+		SVNPath:      cfg.SVNPath,
+	}
+}
+
+func getAllRepos(db *sql.DB, startID uint64, langs []string, threshold float64, basePath string,
+	opts repo.Options) ([]dbRepo, error) {
+	// gone repositories cannot be fetched anymore
+	inClause := fmt.Sprintf("WHERE id >= %d AND gone_at IS NULL", startID)
 	if langs != nil && len(langs) > 0 {
 		// Quote languages.
+		quoted := make([]string, len(langs))
 		for idx, val := range langs {
-			langs[idx] = "'" + val + "'"
+			quoted[idx] = "'" + val + "'"
+		}
+		langList := strings.Join(quoted, ",")
+
+		if threshold > 0 {
+			// match any language that has a large enough share of the code
+			inClause += fmt.Sprintf(` AND (LOWER(primary_language) IN (%s) OR id IN (
+				SELECT repository_id FROM (
+					SELECT repository_id, language, bytes,
+					       SUM(bytes) OVER (PARTITION BY repository_id) AS total
+					FROM repository_languages) rl
+				WHERE LOWER(language) IN (%s) AND bytes * 100 > total * %f))`,
+				langList, langList, threshold)
+		} else {
+			inClause += " AND LOWER(primary_language) IN (" + langList + ")"
 		}
-		inClause += " AND LOWER(primary_language) IN (" + strings.Join(langs, ",") + ")"
 	}
 
-	rows, err := db.Query("SELECT id, vcs, clone_path, clone_url FROM repositories " + inClause + " ORDER BY id")
+	rows, err := db.Query("SELECT id, vcs, clone_path, clone_url, previous_clone_path FROM repositories " +
+		inClause + " ORDER BY id")
 	if err != nil {
 		glog.Error(err)
 		return nil, err
@@ -283,21 +372,27 @@ func getAllRepos(db *sql.DB, startID uint64, langs []string, basePath string) ([
 
 	for rows.Next() {
 		var vcs, clonePath, cloneURL string
+		var previousClonePath sql.NullString
 		var id uint64
-		if err := rows.Scan(&id, &vcs, &clonePath, &cloneURL); err != nil {
+		if err := rows.Scan(&id, &vcs, &clonePath, &cloneURL, &previousClonePath); err != nil {
 			glog.Error(err)
 			continue
 		}
 
 		var newRepo repo.Repo
 		var err error
-		newRepo, err = repo.New(vcs, filepath.Join(basePath, clonePath), cloneURL)
+		newRepo, err = repo.NewWithOptions(vcs, filepath.Join(basePath, clonePath), cloneURL, opts)
 		if err != nil {
 			glog.Error(err)
 			continue
 		}
 
-		repos = append(repos, dbRepo{Repo: newRepo, id: id})
+		r := dbRepo{Repo: newRepo, id: id}
+		if previousClonePath.Valid {
+			r.previousPath = filepath.Join(basePath, previousClonePath.String)
+		}
+
+		repos = append(repos, r)
 	}
 
 	return repos, nil
@@ -347,6 +442,7 @@ var (
 	configPath      = flag.String("c", "", "configuration file")
 	disableCrawlers = flag.Bool("disable-crawlers", false, "disable the data crawlers")
 	disableFetcher  = flag.Bool("disable-fetcher", false, "disable the repositories fetcher")
+	resetCursors    = flag.Bool("reset-cursors", false, "reset the crawlers progress and start over from their since_id")
 )
 
 func main() {
@@ -375,6 +471,16 @@ func main() {
 	}
 	defer db.Close()
 
+	if *resetCursors {
+		var names []string
+		for _, crawlerConfig := range cfg.Crawlers {
+			names = append(names, crawlerConfig.Name)
+		}
+		if err := crawlers.ResetCursors(db, names...); err != nil {
+			fatal(err)
+		}
+	}
+
 	var cs []crawlers.Crawler
 
 	for _, crawlerConfig := range cfg.Crawlers {
@@ -0,0 +1,65 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMoveCloneFiles(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "crawld-move-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	prevPath := filepath.Join(tmpDir, "github.com", "gopher", "crawld")
+	path := filepath.Join(tmpDir, "github.com", "DevMine", "crawld")
+	if err := os.MkdirAll(prevPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(prevPath+".tar", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !moveCloneFiles(prevPath, path) {
+		t.Fatal("moveCloneFiles: expected the clone to be moved")
+	}
+	for _, p := range []string{path, path + ".tar"} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("moveCloneFiles: %s not moved: %v", p, err)
+		}
+	}
+
+	if !moveCloneFiles(path, path) {
+		t.Error("moveCloneFiles: expected a renamed back repository to be moved")
+	}
+}
+
+func TestMoveCloneFilesDestinationExists(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "crawld-move-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	prevPath := filepath.Join(tmpDir, "gopher", "crawld")
+	path := filepath.Join(tmpDir, "DevMine", "crawld")
+	for _, p := range []string{prevPath, path} {
+		if err := os.MkdirAll(p, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if moveCloneFiles(prevPath, path) {
+		t.Error("moveCloneFiles: expected a failure when the destination already exists")
+	}
+	if _, err := os.Stat(prevPath); err != nil {
+		t.Errorf("moveCloneFiles: expected %s to be kept: %v", prevPath, err)
+	}
+}
@@ -0,0 +1,869 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/DevMine/crawld/config"
+)
+
+// bitbucketDefaultURL is the URL of the Bitbucket Cloud REST API.
+const bitbucketDefaultURL = "https://api.bitbucket.org/2.0/"
+
+// bitbucketCrawler implements the Crawler interface.
+type bitbucketCrawler struct {
+	config.CrawlerConfig
+	*restClient
+
+	db *sql.DB
+}
+
+// ensure that bitbucketCrawler implements the Crawler interface
+var _ Crawler = (*bitbucketCrawler)(nil)
+
+// bbLink is a hypermedia link of the Bitbucket API.
+type bbLink struct {
+	Href *string `json:"href"`
+	Name *string `json:"name"`
+}
+
+// bbLinks groups the hypermedia links of a Bitbucket resource.
+type bbLinks struct {
+	HTML   *bbLink  `json:"html"`
+	Avatar *bbLink  `json:"avatar"`
+	Clone  []bbLink `json:"clone"`
+}
+
+// bbAccount represents a Bitbucket user account.
+type bbAccount struct {
+	UUID        *string    `json:"uuid"`
+	Type        *string    `json:"type"`
+	Nickname    *string    `json:"nickname"`
+	DisplayName *string    `json:"display_name"`
+	AccountID   *string    `json:"account_id"`
+	Links       *bbLinks   `json:"links"`
+	CreatedOn   *time.Time `json:"created_on"`
+}
+
+// bbWorkspace represents a Bitbucket workspace (formerly known as team).
+type bbWorkspace struct {
+	UUID      *string    `json:"uuid"`
+	Slug      *string    `json:"slug"`
+	Name      *string    `json:"name"`
+	Links     *bbLinks   `json:"links"`
+	CreatedOn *time.Time `json:"created_on"`
+}
+
+// bbRepository represents a Bitbucket repository.
+type bbRepository struct {
+	UUID        *string       `json:"uuid"`
+	Name        *string       `json:"name"`
+	Slug        *string       `json:"slug"`
+	FullName    *string       `json:"full_name"`
+	Description *string       `json:"description"`
+	Website     *string       `json:"website"`
+	SCM         *string       `json:"scm"`
+	Language    *string       `json:"language"`
+	IsPrivate   *bool         `json:"is_private"`
+	HasIssues   *bool         `json:"has_issues"`
+	HasWiki     *bool         `json:"has_wiki"`
+	Size        *int64        `json:"size"`
+	Owner       *bbAccount    `json:"owner"`
+	Workspace   *bbWorkspace  `json:"workspace"`
+	Parent      *bbRepository `json:"parent"`
+	Links       *bbLinks      `json:"links"`
+	Mainbranch  *struct {
+		Name *string `json:"name"`
+	} `json:"mainbranch"`
+	CreatedOn *time.Time `json:"created_on"`
+	UpdatedOn *time.Time `json:"updated_on"`
+}
+
+// bbRepositoriesPage is a page of Bitbucket repositories.
+type bbRepositoriesPage struct {
+	Values []bbRepository `json:"values"`
+	Next   string         `json:"next"`
+}
+
+// bbMembersPage is a page of Bitbucket workspace members.
+type bbMembersPage struct {
+	Values []struct {
+		User *bbAccount `json:"user"`
+	} `json:"values"`
+	Next string `json:"next"`
+}
+
+// newBitbucketCrawler creates a new Bitbucket crawler.
+func newBitbucketCrawler(cfg config.CrawlerConfig, db *sql.DB) (*bitbucketCrawler, error) {
+	if db == nil {
+		return nil, errors.New("database session cannot be nil")
+	}
+
+	client, err := newBitbucketClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bitbucketCrawler{CrawlerConfig: cfg, restClient: client, db: db}, nil
+}
+
+// newBitbucketClient creates a REST client for the Bitbucket API. The API URL
+// can be overridden with the base URL of the crawler configuration.
+func newBitbucketClient(cfg config.CrawlerConfig) (*restClient, error) {
+	baseURL := cfg.BaseURL
+	if len(strings.Trim(baseURL, " ")) == 0 {
+		baseURL = bitbucketDefaultURL
+	}
+
+	var authorization string
+	if len(strings.Trim(cfg.OAuthAccessToken, " ")) != 0 {
+		authorization = "Bearer " + cfg.OAuthAccessToken
+	}
+
+	return newRestClient(baseURL, authorization)
+}
+
+// Crawl implements the Crawl() method of the Crawler interface.
+func (b *bitbucketCrawler) Crawl() {
+	n := b.Limit
+	hasLimit := n > 0
+
+	next := "repositories?pagelen=100"
+ResultsLoop:
+	for len(next) != 0 {
+		tmp := b.call(b.fetchRepositories, next)
+		page, ok := tmp.(*bbRepositoriesPage)
+		if !ok {
+			break
+		}
+
+		for _, repo := range page.Values {
+			if n == 0 && hasLimit {
+				break ResultsLoop
+			}
+
+			if err := verifyBbRepo(&repo); err != nil {
+				glog.Error(err)
+				continue
+			}
+
+			// skip? fork repos
+			if repo.Parent != nil && !b.Fork {
+				continue
+			}
+
+			if ok, err := isLanguageWanted(b.Languages, repo.Language); err != nil {
+				glog.Error(err)
+				continue
+			} else if !ok {
+				continue
+			}
+
+			// skip when the method fails because the repository is not
+			// saved into the DB
+			if !b.insertOrUpdateRepo(&repo) {
+				continue
+			}
+
+			n--
+		}
+
+		next = page.Next
+	}
+}
+
+// fetchRepositories fetches a page of public Bitbucket repositories.
+//
+// args expects 1 value:
+// - pageURL: the URL of the page to fetch
+//
+// It returns a *bbRepositoriesPage.
+func (b *bitbucketCrawler) fetchRepositories(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var pageURL string
+	switch args[0].(type) {
+	case string:
+		pageURL = args[0].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	page := new(bbRepositoriesPage)
+	if _, err := b.get(pageURL, nil, page); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// fetchUser fetches information about a Bitbucket user.
+//
+// args expects 1 value:
+// - uuid: the Bitbucket user UUID
+//
+// It returns a *bbAccount.
+func (b *bitbucketCrawler) fetchUser(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var uuid string
+	switch args[0].(type) {
+	case string:
+		uuid = args[0].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	user := new(bbAccount)
+	if _, err := b.get("users/"+url.QueryEscape(uuid), nil, user); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// fetchWorkspace fetches information about a Bitbucket workspace.
+//
+// args expects 1 value:
+// - slug: the workspace slug
+//
+// It returns a *bbWorkspace.
+func (b *bitbucketCrawler) fetchWorkspace(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var slug string
+	switch args[0].(type) {
+	case string:
+		slug = args[0].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	workspace := new(bbWorkspace)
+	if _, err := b.get("workspaces/"+url.QueryEscape(slug), nil, workspace); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+// fetchWorkspaceMembers fetches a page of members of a Bitbucket workspace.
+//
+// args expects 1 value:
+// - pageURL: the URL of the page to fetch
+//
+// It returns a *bbMembersPage.
+func (b *bitbucketCrawler) fetchWorkspaceMembers(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var pageURL string
+	switch args[0].(type) {
+	case string:
+		pageURL = args[0].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	page := new(bbMembersPage)
+	if _, err := b.get(pageURL, nil, page); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// getRepoID returns the repository id of repo in repositories table.
+// If repo is not in the table, then 0 is returned. If an error occurs, -1 is
+// returned.
+func (b *bitbucketCrawler) getRepoID(repo *bbRepository) int {
+	if repo == nil {
+		glog.Error("'repo' arg given is nil")
+		return -1
+	}
+
+	var id int
+	err := b.db.QueryRow("SELECT repository_id FROM bb_repositories WHERE bitbucket_uuid=$1", repo.UUID).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// getBbRepoID returns the bitbucket repository id of repo in bb_repositories
+// table.
+// If repo is not in the table, then 0 is returned. If an error occurs, -1 is
+// returned.
+func (b *bitbucketCrawler) getBbRepoID(repo *bbRepository) int {
+	if repo == nil {
+		glog.Error("'repo' arg given is nil")
+		return -1
+	}
+
+	var id int
+	err := b.db.QueryRow("SELECT id FROM bb_repositories WHERE bitbucket_uuid=$1", repo.UUID).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// getBbWorkspaceID returns the bitbucket workspace id of workspace in
+// bb_workspaces table.
+// If workspace is not in the table, then 0 is returned. If an error occurs,
+// -1 is returned.
+func (b *bitbucketCrawler) getBbWorkspaceID(workspace *bbWorkspace) int {
+	if workspace == nil {
+		glog.Error("'workspace' arg given is nil")
+		return -1
+	}
+
+	var id int
+	err := b.db.QueryRow("SELECT id FROM bb_workspaces WHERE bitbucket_uuid=$1", workspace.UUID).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// getBbUserID returns the bitbucket user id of user in bb_users table.
+// If user is not in the table, then 0 is returned. If an error occurs, -1 is
+// returned.
+func (b *bitbucketCrawler) getBbUserID(user *bbAccount) int {
+	if user == nil {
+		glog.Error("'user' arg given is nil")
+		return -1
+	}
+
+	var id int
+	err := b.db.QueryRow("SELECT id FROM bb_users WHERE bitbucket_uuid=$1", user.UUID).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// getUserID returns the user id of user in users table.
+// If user is not in the table, then 0 is returned. If an error occurs, -1 is
+// returned.
+func (b *bitbucketCrawler) getUserID(user *bbAccount) int {
+	if user == nil {
+		glog.Error("'user' arg given is nil")
+		return -1
+	}
+
+	var id int
+	err := b.db.QueryRow("SELECT user_id FROM bb_users WHERE bitbucket_uuid=$1", user.UUID).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// insertOrUpdateRepo inserts or updates a repository. It also inserts or
+// updates related Bitbucket repository, users, Bitbucket users and Bitbucket
+// workspace (if any).
+func (b *bitbucketCrawler) insertOrUpdateRepo(repo *bbRepository) bool {
+	if repo == nil {
+		glog.Error("'repo' arg given is nil")
+		return false
+	}
+	glog.Infof("insert or update repository: %s", *repo.FullName)
+
+	cloneURL := bbCloneURL(repo)
+	if len(cloneURL) == 0 {
+		glog.Errorf("repository %s has no https clone URL", *repo.FullName)
+		return false
+	}
+
+	vcs := "git"
+	if *repo.SCM == "hg" {
+		vcs = "hg"
+	}
+
+	clonePath := strings.ToLower(filepath.Join(*repo.Language, b.baseURL.Host, *repo.FullName))
+	repoFields := []string{"name", "primary_language", "clone_url", "clone_path", "vcs"}
+
+	var query string
+	if id := b.getRepoID(repo); id > 0 {
+		query = genUpdateQuery("repositories", id, repoFields...)
+	} else if id == 0 {
+		query = genInsQuery("repositories", repoFields...)
+	} else {
+		return false
+	}
+
+	var repoID int64
+	err := b.db.QueryRow(query+" RETURNING id", repo.Name, repo.Language, cloneURL, clonePath, vcs).Scan(&repoID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if !b.insertOrUpdateBbRepo(repoID, repo) {
+		return false
+	}
+
+	if *repo.Owner.Type == "user" {
+		return b.insertOrUpdateUser(*repo.Owner.UUID, repoID, 0)
+	}
+
+	if repo.Workspace == nil || repo.Workspace.Slug == nil {
+		glog.Errorf("repository %s is owned by a team but has no workspace", *repo.FullName)
+		return false
+	}
+
+	return b.insertOrUpdateBbWorkspace(*repo.Workspace.Slug, repoID)
+}
+
+// insertOrUpdateBbRepo inserts, or updates, a bitbucket repository in the
+// database.
+func (b *bitbucketCrawler) insertOrUpdateBbRepo(repoID int64, repo *bbRepository) bool {
+	if repo == nil {
+		glog.Error("'repo' arg given is nil")
+		return false
+	}
+	glog.Infof("insert or update bitbucket repository: %s", *repo.FullName)
+
+	var mainBranch, htmlURL, parentFullName *string
+	if repo.Mainbranch != nil {
+		mainBranch = repo.Mainbranch.Name
+	}
+	if repo.Links != nil && repo.Links.HTML != nil {
+		htmlURL = repo.Links.HTML.Href
+	}
+	if repo.Parent != nil {
+		parentFullName = repo.Parent.FullName
+	}
+
+	bbRepoFields := []string{
+		"repository_id",
+		"bitbucket_uuid",
+		"full_name",
+		"description",
+		"website",
+		"scm",
+		"fork",
+		"parent_full_name",
+		"main_branch",
+		"html_url",
+		"has_issues",
+		"has_wiki",
+		"size_in_bytes",
+		"created_at",
+		"updated_at",
+	}
+
+	var query string
+	if id := b.getBbRepoID(repo); id > 0 {
+		query = genUpdateQuery("bb_repositories", id, bbRepoFields...)
+	} else if id == 0 {
+		query = genInsQuery("bb_repositories", bbRepoFields...)
+	} else {
+		return false
+	}
+
+	_, err := b.db.Exec(query,
+		repoID,
+		repo.UUID,
+		repo.FullName,
+		repo.Description,
+		repo.Website,
+		repo.SCM,
+		repo.Parent != nil,
+		parentFullName,
+		mainBranch,
+		htmlURL,
+		repo.HasIssues,
+		repo.HasWiki,
+		repo.Size,
+		formatTime(repo.CreatedOn),
+		formatTime(repo.UpdatedOn))
+
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// insertOrUpdateBbWorkspace inserts, or updates, a bitbucket workspace into
+// the database. Members of the workspace are linked to the given repository.
+func (b *bitbucketCrawler) insertOrUpdateBbWorkspace(slug string, repoID int64) bool {
+	glog.Infof("insert or update bitbucket workspace: %s", slug)
+
+	tmp := b.call(b.fetchWorkspace, slug)
+	var workspace *bbWorkspace
+	switch tmp.(type) {
+	case *bbWorkspace:
+		workspace = tmp.(*bbWorkspace)
+	default:
+		glog.Error("invalid function return type")
+		return false
+	}
+
+	var htmlURL, avatarURL *string
+	if workspace.Links != nil {
+		if workspace.Links.HTML != nil {
+			htmlURL = workspace.Links.HTML.Href
+		}
+		if workspace.Links.Avatar != nil {
+			avatarURL = workspace.Links.Avatar.Href
+		}
+	}
+
+	bbWorkspaceFields := []string{
+		"bitbucket_uuid",
+		"slug",
+		"name",
+		"html_url",
+		"avatar_url",
+		"created_at",
+	}
+
+	var query string
+	if id := b.getBbWorkspaceID(workspace); id > 0 {
+		query = genUpdateQuery("bb_workspaces", id, bbWorkspaceFields...)
+	} else if id == 0 {
+		query = genInsQuery("bb_workspaces", bbWorkspaceFields...)
+	} else {
+		return false
+	}
+
+	var workspaceID int64
+	err := b.db.QueryRow(query+" RETURNING id",
+		workspace.UUID,
+		workspace.Slug,
+		workspace.Name,
+		htmlURL,
+		avatarURL,
+		formatTime(workspace.CreatedOn)).Scan(&workspaceID)
+
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	uuids, ok := b.workspaceMemberUUIDs(slug)
+	if !ok {
+		return false
+	}
+
+	for _, uuid := range uuids {
+		if !b.insertOrUpdateUser(uuid, repoID, workspaceID) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// workspaceMemberUUIDs returns the UUIDs of the members of a Bitbucket
+// workspace. Only the members of a workspace can list its members, hence a
+// workspace with no visible members is not an error.
+func (b *bitbucketCrawler) workspaceMemberUUIDs(slug string) ([]string, bool) {
+	var uuids []string
+	for next := "workspaces/" + url.QueryEscape(slug) + "/members?pagelen=100"; len(next) != 0; {
+		tmp, err := b.callErr(b.fetchWorkspaceMembers, next)
+		if err == errUnavailable {
+			glog.Infof("members of workspace %s are not visible", slug)
+			return nil, true
+		}
+
+		page, ok := tmp.(*bbMembersPage)
+		if !ok {
+			glog.Error("invalid function return type")
+			return nil, false
+		}
+
+		for _, member := range page.Values {
+			if member.User == nil || member.User.UUID == nil {
+				glog.Error("'member' has nil user UUID")
+				continue
+			}
+			uuids = append(uuids, *member.User.UUID)
+		}
+
+		next = page.Next
+	}
+
+	return uuids, true
+}
+
+// insertOrUpdateUser inserts, or updates, a bitbucket user into the database.
+func (b *bitbucketCrawler) insertOrUpdateUser(uuid string, repoID int64, workspaceID int64) bool {
+	glog.Infof("insert or update user: %s", uuid)
+
+	if repoID <= 0 {
+		glog.Error("trying to insert a user without linked Bitbucket repository")
+		return false
+	}
+
+	tmp := b.call(b.fetchUser, uuid)
+	var user *bbAccount
+	switch tmp.(type) {
+	case *bbAccount:
+		user = tmp.(*bbAccount)
+	default:
+		glog.Error("invalid function return type")
+		return false
+	}
+
+	userFields := []string{"username", "name", "email"}
+
+	var query string
+	if id := b.getUserID(user); id > 0 {
+		query = genUpdateQuery("users", id, userFields...)
+	} else if id == 0 {
+		query = genInsQuery("users", userFields...)
+	} else {
+		return false
+	}
+
+	var userID int64
+	err := b.db.QueryRow(query+" RETURNING id", user.Nickname, user.DisplayName, nil).Scan(&userID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if !linkUserToRepo(b.db, userID, repoID) {
+		return false
+	}
+
+	if !b.insertOrUpdateBbUser(userID, user, workspaceID) {
+		return false
+	}
+
+	return true
+}
+
+// insertOrUpdateBbUser inserts, or updates, a bitbucket user into the
+// database.
+func (b *bitbucketCrawler) insertOrUpdateBbUser(userID int64, user *bbAccount, workspaceID int64) bool {
+	if user == nil {
+		glog.Error("'user' arg given is nil")
+		return false
+	}
+	glog.Infof("insert or update bitbucket user: %s", *user.UUID)
+
+	if userID <= 0 {
+		glog.Error("trying to insert a bitbucket user but no user ID given")
+		return false
+	}
+
+	var htmlURL, avatarURL *string
+	if user.Links != nil {
+		if user.Links.HTML != nil {
+			htmlURL = user.Links.HTML.Href
+		}
+		if user.Links.Avatar != nil {
+			avatarURL = user.Links.Avatar.Href
+		}
+	}
+
+	bbUserFields := []string{
+		"user_id",
+		"bitbucket_uuid",
+		"account_id",
+		"nickname",
+		"display_name",
+		"html_url",
+		"avatar_url",
+		"created_at",
+	}
+
+	var query string
+	if id := b.getBbUserID(user); id > 0 {
+		query = genUpdateQuery("bb_users", id, bbUserFields...)
+	} else if id == 0 {
+		query = genInsQuery("bb_users", bbUserFields...)
+	} else {
+		return false
+	}
+
+	var bbUserID int64
+	err := b.db.QueryRow(query+" RETURNING id",
+		userID,
+		user.UUID,
+		user.AccountID,
+		user.Nickname,
+		user.DisplayName,
+		htmlURL,
+		avatarURL,
+		formatTime(user.CreatedOn)).Scan(&bbUserID)
+
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if workspaceID != 0 {
+		if !b.linkBbUserToBbWorkspace(bbUserID, workspaceID) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isBbUserLinkedToBbWorkspace checks whether a bitbucket user is linked to the
+// given bitbucket workspace or not.
+func (b *bitbucketCrawler) isBbUserLinkedToBbWorkspace(bbUserID, workspaceID int64) bool {
+	row := b.db.QueryRow(
+		`SELECT COUNT(*) AS total
+		 FROM bb_users_workspaces
+		 WHERE bb_user_id = $1 AND bb_workspace_id = $2`, bbUserID, workspaceID)
+
+	var total int64
+	if err := row.Scan(&total); err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return total > 0
+}
+
+// linkBbUserToBbWorkspace links a bitbucket user to the given bitbucket
+// workspace.
+func (b *bitbucketCrawler) linkBbUserToBbWorkspace(bbUserID, workspaceID int64) bool {
+	if b.isBbUserLinkedToBbWorkspace(bbUserID, workspaceID) {
+		return true
+	}
+
+	fields := []string{"bb_user_id", "bb_workspace_id"}
+
+	query := genInsQuery("bb_users_workspaces", fields...)
+
+	_, err := b.db.Exec(query, bbUserID, workspaceID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// bbCloneURL returns the https clone URL of a bitbucket repository, without
+// any user information, or an empty string if there is none.
+func bbCloneURL(repo *bbRepository) string {
+	if repo.Links == nil {
+		return ""
+	}
+
+	for _, link := range repo.Links.Clone {
+		if link.Name == nil || *link.Name != "https" || link.Href == nil {
+			continue
+		}
+
+		u, err := url.Parse(*link.Href)
+		if err != nil {
+			glog.Error(err)
+			return ""
+		}
+		u.User = nil
+
+		return u.String()
+	}
+
+	return ""
+}
+
+// verifyBbRepo checks all essential fields of a bbRepository structure for
+// nil values. An error is returned if one of the essential field is nil.
+func verifyBbRepo(repo *bbRepository) error {
+	if repo == nil {
+		return newInvalidStructError("verifyBbRepo: repo is nil")
+	}
+
+	var err *invalidStructError
+	if repo.UUID == nil {
+		err = newInvalidStructError("verifyBbRepo: contains nil fields:").AddField("UUID")
+	} else {
+		err = newInvalidStructError("verifyBbRepo: repo " + *repo.UUID + " contains nil fields: ")
+	}
+
+	if repo.Name == nil {
+		err.AddField("Name")
+	}
+
+	if repo.FullName == nil {
+		err.AddField("FullName")
+	}
+
+	if repo.Language == nil {
+		err.AddField("Language")
+	}
+
+	if repo.SCM == nil {
+		err.AddField("SCM")
+	}
+
+	if repo.Owner == nil {
+		err.AddField("Owner")
+	} else {
+		if repo.Owner.Type == nil {
+			err.AddField("Owner.Type")
+		}
+		if repo.Owner.UUID == nil {
+			err.AddField("Owner.UUID")
+		}
+	}
+
+	if err.FieldsLen() > 0 {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DevMine/crawld/config"
+)
+
+func TestBitbucketFetchRepositories(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2.0/repositories" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("page") != "2" {
+			fmt.Fprintf(w, `{
+				"values": [{
+					"uuid": "{1}",
+					"name": "crawld",
+					"full_name": "devmine/crawld",
+					"scm": "hg",
+					"language": "go",
+					"owner": {"uuid": "{2}", "type": "user"},
+					"links": {"clone": [
+						{"name": "ssh", "href": "ssh://hg@bitbucket.org/devmine/crawld"},
+						{"name": "https", "href": "https://alice@bitbucket.org/devmine/crawld"}
+					]}
+				}],
+				"next": "%s/2.0/repositories?pagelen=100&page=2"
+			}`, ts.URL)
+			return
+		}
+		fmt.Fprint(w, `{"values": []}`)
+	}))
+	defer ts.Close()
+
+	client, err := newBitbucketClient(config.CrawlerConfig{BaseURL: ts.URL + "/2.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &bitbucketCrawler{restClient: client}
+
+	var repos []bbRepository
+	for next := "repositories?pagelen=100"; len(next) != 0; {
+		page, ok := b.call(b.fetchRepositories, next).(*bbRepositoriesPage)
+		if !ok {
+			t.Fatal("fetchRepositories: expected a page of repositories")
+		}
+		repos = append(repos, page.Values...)
+		next = page.Next
+	}
+
+	if len(repos) != 1 {
+		t.Fatalf("len(repos): expected 1, found %d", len(repos))
+	}
+
+	if err := verifyBbRepo(&repos[0]); err != nil {
+		t.Error(err)
+	}
+
+	expected := "https://bitbucket.org/devmine/crawld"
+	if cloneURL := bbCloneURL(&repos[0]); cloneURL != expected {
+		t.Errorf("bbCloneURL: expected '%s', found '%s'", expected, cloneURL)
+	}
+}
+
+func TestBitbucketWorkspaceMemberUUIDs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2.0/workspaces/devmine/members":
+			fmt.Fprint(w, `{"values": [{"user": {"uuid": "{2}"}}, {"user": null}]}`)
+		case "/2.0/workspaces/private/members":
+			// only the members of a workspace can list its members
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := newBitbucketClient(config.CrawlerConfig{BaseURL: ts.URL + "/2.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &bitbucketCrawler{restClient: client}
+
+	uuids, ok := b.workspaceMemberUUIDs("devmine")
+	if !ok || len(uuids) != 1 || uuids[0] != "{2}" {
+		t.Errorf("workspaceMemberUUIDs(devmine): expected [{2}], found %v (ok: %v)", uuids, ok)
+	}
+
+	uuids, ok = b.workspaceMemberUUIDs("private")
+	if !ok || len(uuids) != 0 {
+		t.Errorf("workspaceMemberUUIDs(private): expected no visible members, found %v (ok: %v)", uuids, ok)
+	}
+}
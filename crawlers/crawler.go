@@ -25,9 +25,21 @@ func New(cfg config.CrawlerConfig, db *sql.DB) (Crawler, error) {
 	var newCrawler Crawler
 	var err error
 
+	if len(cfg.Name) == 0 {
+		cfg.Name = cfg.Type
+	}
+
 	switch cfg.Type {
 	case "github":
 		newCrawler, err = newGitHubCrawler(cfg, db)
+	case "gitlab":
+		newCrawler, err = newGitLabCrawler(cfg, db)
+	case "bitbucket":
+		newCrawler, err = newBitbucketCrawler(cfg, db)
+	case "gitea", "gogs", "forgejo":
+		newCrawler, err = newGiteaCrawler(cfg, db)
+	case "seedlist":
+		newCrawler, err = newSeedListCrawler(cfg, db)
 	default:
 		return nil, errors.New("unsupported crawler type: " + cfg.Type)
 	}
@@ -0,0 +1,76 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// getCursor returns the ID of the last repository processed by the crawler
+// identified by name, as stored in crawler_cursors table.
+// If the crawler has no cursor, then 0 is returned. If an error occurs, -1 is
+// returned.
+func getCursor(db *sql.DB, name string) int {
+	var sinceID int
+	err := db.QueryRow("SELECT since_id FROM crawler_cursors WHERE crawler_name=$1", name).Scan(&sinceID)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return sinceID
+}
+
+// saveCursor stores the ID of the last repository processed by the crawler
+// identified by name.
+func saveCursor(db *sql.DB, name string, sinceID int) bool {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	res, err := db.Exec(
+		`UPDATE crawler_cursors
+		 SET since_id = $1, updated_at = $2
+		 WHERE crawler_name = $3`, sinceID, now, name)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		glog.Error(err)
+		return false
+	} else if n > 0 {
+		return true
+	}
+
+	query := genInsQuery("crawler_cursors", "crawler_name", "since_id", "updated_at")
+	if _, err := db.Exec(query, name, sinceID, now); err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// ResetCursors removes the cursors of the crawlers identified by names so
+// that they start over from the since_id of their configuration.
+func ResetCursors(db *sql.DB, names ...string) error {
+	if db == nil {
+		return errors.New("database session cannot be nil")
+	}
+
+	for _, name := range names {
+		if _, err := db.Exec("DELETE FROM crawler_cursors WHERE crawler_name=$1", name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -13,6 +13,8 @@ import (
 var (
 	errTooManyCall      = errors.New("API rate limit exceeded")
 	errUnavailable      = errors.New("resource unavailable")
+	errNotModified      = errors.New("resource not modified")
+	errGone             = errors.New("resource not found or unavailable for legal reasons")
 	errRuntime          = errors.New("runtime error")
 	errInvalidArgs      = errors.New("invalid arguments")
 	errNilArg           = errors.New("nil argument")
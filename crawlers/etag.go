@@ -0,0 +1,124 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// validators are the values of the ETag and Last-Modified headers of a
+// response, used to make conditional requests.
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+// etagCache stores the validators of API resources in the gh_etags table.
+// Validators of a fetched resource are only stored once committed, that is
+// once the resource has been saved into the database. Otherwise, a failure
+// to save the resource would never be recovered since the next conditional
+// request would tell that the resource has not been modified.
+// A nil *etagCache is valid and never has any validators.
+type etagCache struct {
+	db      *sql.DB
+	pending map[string]validators
+}
+
+// newETagCache creates a new ETag cache.
+func newETagCache(db *sql.DB) *etagCache {
+	return &etagCache{db: db, pending: map[string]validators{}}
+}
+
+// setConditionalHeaders sets the headers of a conditional request for the
+// resource located at urlStr, if the validators of the resource are known.
+func (c *etagCache) setConditionalHeaders(urlStr string, req *http.Request) {
+	if c == nil {
+		return
+	}
+
+	var etag, lastModified sql.NullString
+	err := c.db.QueryRow("SELECT etag, last_modified FROM gh_etags WHERE url=$1", urlStr).Scan(&etag, &lastModified)
+	switch {
+	case err == sql.ErrNoRows:
+		return
+	case err != nil:
+		glog.Error(err)
+		return
+	}
+
+	if etag.Valid && len(etag.String) != 0 {
+		req.Header.Set("If-None-Match", etag.String)
+	}
+	if lastModified.Valid && len(lastModified.String) != 0 {
+		req.Header.Set("If-Modified-Since", lastModified.String)
+	}
+}
+
+// set records the validators of the resource located at urlStr from the
+// headers of the response. They are stored once committed.
+func (c *etagCache) set(urlStr string, header http.Header) {
+	if c == nil {
+		return
+	}
+
+	v := validators{etag: header.Get("ETag"), lastModified: header.Get("Last-Modified")}
+	if len(v.etag) == 0 && len(v.lastModified) == 0 {
+		return
+	}
+	c.pending[urlStr] = v
+}
+
+// commit stores the validators of the resource located at urlStr, if any.
+func (c *etagCache) commit(urlStr string) {
+	if c == nil {
+		return
+	}
+
+	v, ok := c.pending[urlStr]
+	if !ok {
+		return
+	}
+	delete(c.pending, urlStr)
+
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	res, err := c.db.Exec(
+		`UPDATE gh_etags
+		 SET etag = $1, last_modified = $2, updated_at = $3
+		 WHERE url = $4`, v.etag, v.lastModified, now, urlStr)
+	if err != nil {
+		glog.Error(err)
+		return
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		glog.Error(err)
+		return
+	} else if n > 0 {
+		return
+	}
+
+	query := genInsQuery("gh_etags", "url", "etag", "last_modified", "updated_at")
+	if _, err := c.db.Exec(query, urlStr, v.etag, v.lastModified, now); err != nil {
+		glog.Error(err)
+	}
+}
+
+// forget removes the validators of the resource located at urlStr so that
+// the resource is fully fetched next time.
+func (c *etagCache) forget(urlStr string) {
+	if c == nil {
+		return
+	}
+
+	delete(c.pending, urlStr)
+	if _, err := c.db.Exec("DELETE FROM gh_etags WHERE url=$1", urlStr); err != nil {
+		glog.Error(err)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestGetConditional(t *testing.T) {
+	g, closeFn := newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users/unchanged" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		expectRequest(t, r, "GET", "/users/changed")
+		w.Header().Set("ETag", `"abc"`)
+		fmt.Fprint(w, `{"login": "changed"}`)
+	})
+	defer closeFn()
+
+	user := new(github.User)
+	if _, err := g.getConditional(ghUserURL("changed"), user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Login == nil || *user.Login != "changed" {
+		t.Errorf("getConditional: expected user 'changed', found %v", user.Login)
+	}
+
+	if _, err := g.getConditional(ghUserURL("unchanged"), new(github.User)); err != errNotModified {
+		t.Errorf("getConditional: expected errNotModified, found %v", err)
+	}
+}
+
+func TestETagCachePending(t *testing.T) {
+	c := newETagCache(nil)
+
+	h := http.Header{}
+	c.set("users/nobody", h)
+	if _, ok := c.pending["users/nobody"]; ok {
+		t.Error("set: validators recorded for a response without ETag nor Last-Modified")
+	}
+
+	h.Set("ETag", `"abc"`)
+	c.set("users/somebody", h)
+	if v := c.pending["users/somebody"]; v.etag != `"abc"` {
+		t.Errorf("set: expected ETag '\"abc\"', found '%s'", v.etag)
+	}
+
+	// a nil cache has no effect
+	var nilCache *etagCache
+	nilCache.set("users/somebody", h)
+	nilCache.commit("users/somebody")
+}
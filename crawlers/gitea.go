@@ -0,0 +1,965 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/DevMine/crawld/config"
+)
+
+const (
+	// giteaAPIPath is the path to the Gitea REST API, relative to the
+	// instance URL. Gogs and Forgejo expose a compatible API at the same
+	// location.
+	giteaAPIPath = "api/v1/"
+
+	// giteaPageSize is the number of results requested per page. Gitea
+	// caps it to 50 by default.
+	giteaPageSize = 50
+)
+
+// giteaCrawler implements the Crawler interface. It crawls Gitea instances
+// as well as Gogs and Forgejo instances.
+type giteaCrawler struct {
+	config.CrawlerConfig
+	*restClient
+
+	db *sql.DB
+
+	// instanceURL is the URL of the crawled instance. It is used to tell
+	// apart users and organizations of different instances.
+	instanceURL string
+}
+
+// ensure that giteaCrawler implements the Crawler interface
+var _ Crawler = (*giteaCrawler)(nil)
+
+// gtUser represents a Gitea user.
+type gtUser struct {
+	ID             *int       `json:"id"`
+	Login          *string    `json:"login"`
+	FullName       *string    `json:"full_name"`
+	Email          *string    `json:"email"`
+	AvatarURL      *string    `json:"avatar_url"`
+	Location       *string    `json:"location"`
+	Website        *string    `json:"website"`
+	Description    *string    `json:"description"`
+	FollowersCount *int       `json:"followers_count"`
+	FollowingCount *int       `json:"following_count"`
+	Created        *time.Time `json:"created"`
+}
+
+// gtOrganization represents a Gitea organization.
+type gtOrganization struct {
+	ID          *int    `json:"id"`
+	Name        *string `json:"name"`
+	UserName    *string `json:"username"`
+	FullName    *string `json:"full_name"`
+	Description *string `json:"description"`
+	Website     *string `json:"website"`
+	Location    *string `json:"location"`
+	AvatarURL   *string `json:"avatar_url"`
+}
+
+// gtRepository represents a Gitea repository.
+type gtRepository struct {
+	ID              *int       `json:"id"`
+	Owner           *gtUser    `json:"owner"`
+	Name            *string    `json:"name"`
+	FullName        *string    `json:"full_name"`
+	Description     *string    `json:"description"`
+	Website         *string    `json:"website"`
+	Language        *string    `json:"language"`
+	Fork            *bool      `json:"fork"`
+	Mirror          *bool      `json:"mirror"`
+	Archived        *bool      `json:"archived"`
+	Empty           *bool      `json:"empty"`
+	HTMLURL         *string    `json:"html_url"`
+	CloneURL        *string    `json:"clone_url"`
+	DefaultBranch   *string    `json:"default_branch"`
+	StarsCount      *int       `json:"stars_count"`
+	ForksCount      *int       `json:"forks_count"`
+	WatchersCount   *int       `json:"watchers_count"`
+	OpenIssuesCount *int       `json:"open_issues_count"`
+	Size            *int       `json:"size"`
+	CreatedAt       *time.Time `json:"created_at"`
+	UpdatedAt       *time.Time `json:"updated_at"`
+}
+
+// gtSearchResults corresponds to the results of a repository search.
+type gtSearchResults struct {
+	OK   bool           `json:"ok"`
+	Data []gtRepository `json:"data"`
+}
+
+// newGiteaCrawler creates a new Gitea crawler.
+func newGiteaCrawler(cfg config.CrawlerConfig, db *sql.DB) (*giteaCrawler, error) {
+	if db == nil {
+		return nil, errors.New("database session cannot be nil")
+	}
+
+	client, err := newGiteaClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	instanceURL := strings.TrimSuffix(cfg.BaseURL, "/")
+
+	return &giteaCrawler{CrawlerConfig: cfg, restClient: client, db: db, instanceURL: instanceURL}, nil
+}
+
+// newGiteaClient creates a REST client for the API of the Gitea instance
+// given in the crawler configuration.
+func newGiteaClient(cfg config.CrawlerConfig) (*restClient, error) {
+	if len(strings.Trim(cfg.BaseURL, " ")) == 0 {
+		return nil, errors.New("a base url is required for the " + cfg.Type + " crawler")
+	}
+
+	baseURL := cfg.BaseURL
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	var authorization string
+	if len(strings.Trim(cfg.OAuthAccessToken, " ")) != 0 {
+		authorization = "token " + cfg.OAuthAccessToken
+	}
+
+	return newRestClient(baseURL+giteaAPIPath, authorization)
+}
+
+// Crawl implements the Crawl() method of the Crawler interface.
+func (g *giteaCrawler) Crawl() {
+	n := g.Limit
+	hasLimit := n > 0
+
+	sinceID := g.SinceID
+	if cursor := getCursor(g.db, g.Name); cursor > sinceID {
+		glog.Infof("%s crawler: resuming from repository ID %d", g.Name, cursor)
+		sinceID = cursor
+	}
+
+ResultsLoop:
+	for page := g.startPage(sinceID); ; page++ {
+		tmp := g.call(g.fetchRepositories, page)
+		repos, ok := tmp.([]gtRepository)
+		if !ok || len(repos) == 0 {
+			break
+		}
+
+		for _, repo := range repos {
+			if n == 0 && hasLimit {
+				break ResultsLoop
+			}
+
+			if err := verifyGtRepo(&repo); err != nil {
+				glog.Error(err)
+				continue
+			}
+
+			// the start page may still list repositories that have already
+			// been crawled
+			if *repo.ID <= sinceID {
+				continue
+			}
+			sinceID = *repo.ID
+
+			// skip? fork repos
+			if *repo.Fork && !g.Fork {
+				continue
+			}
+
+			// there is nothing to fetch from empty repositories
+			if repo.Empty != nil && *repo.Empty {
+				continue
+			}
+
+			lang := ""
+			if repo.Language != nil {
+				lang = *repo.Language
+			}
+
+			if ok, err := isLanguageWanted(g.Languages, &lang); err != nil {
+				glog.Error(err)
+				continue
+			} else if !ok {
+				langs, ok := g.call(g.fetchRepositoryLanguages, *repo.Owner.Login, *repo.Name).(map[string]int)
+				if !ok {
+					continue
+				}
+
+				if ok, err := isLanguageWanted(g.Languages, langs); err != nil {
+					glog.Error(err)
+					continue
+				} else if !ok {
+					continue
+				}
+
+				if len(lang) == 0 {
+					lang = primaryLanguageBytes(langs)
+				}
+			}
+
+			// skip when the method fails because the repository is not
+			// saved into the DB
+			if !g.insertOrUpdateRepo(&repo, lang) {
+				continue
+			}
+
+			n--
+		}
+
+		// persist the progress so that the next crawl, or a restart,
+		// resumes from here
+		saveCursor(g.db, g.Name, sinceID)
+
+		if n <= 0 && hasLimit {
+			break
+		}
+	}
+	saveCursor(g.db, g.Name, sinceID)
+}
+
+// startPage returns the first page of the repositories of the instance that
+// lists repositories whose ID is greater than sinceID. The search API cannot
+// start from a given ID but results are sorted by ID, so that the page is
+// looked for by bisection instead of walking through the whole instance. It
+// falls back to the first page if an error occurs.
+func (g *giteaCrawler) startPage(sinceID int) int {
+	if sinceID <= 0 {
+		return 1
+	}
+
+	// isAfter tells whether page is past the repositories whose ID is lower
+	// than or equal to sinceID, ie whether it is empty or ends with a greater
+	// ID
+	isAfter := func(page int) (bool, error) {
+		repos, ok := g.call(g.fetchRepositories, page).([]gtRepository)
+		if !ok {
+			return false, errors.New("cannot fetch the repositories of page " + strconv.Itoa(page))
+		}
+		if len(repos) == 0 {
+			return true, nil
+		}
+
+		last := repos[len(repos)-1]
+		if last.ID == nil {
+			return false, errors.New("'repo' has nil ID field")
+		}
+		return *last.ID > sinceID, nil
+	}
+
+	// the number of pages is unknown, hence it is bounded first
+	lo, hi := 1, 1
+	for {
+		after, err := isAfter(hi)
+		if err != nil {
+			glog.Error(err)
+			return 1
+		}
+		if after {
+			break
+		}
+		lo, hi = hi+1, hi*2
+	}
+
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		after, err := isAfter(mid)
+		if err != nil {
+			glog.Error(err)
+			return 1
+		}
+		if after {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	return lo
+}
+
+// fetchRepositories fetches a page of repositories of the instance.
+//
+// args expects 1 value:
+// - page: the page number, starting at 1
+//
+// It returns a list of repositories.
+func (g *giteaCrawler) fetchRepositories(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var page int
+	switch args[0].(type) {
+	case int:
+		page = args[0].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	params := url.Values{}
+	params.Set("sort", "id")
+	params.Set("order", "asc")
+	params.Set("limit", strconv.Itoa(giteaPageSize))
+	params.Set("page", strconv.Itoa(page))
+
+	results := new(gtSearchResults)
+	if _, err := g.get("repos/search", params, results); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	return results.Data, nil
+}
+
+// fetchRepositoryLanguages fetches all languages related to a repository.
+//
+// args expects 2 values:
+// - owner: the repository owner
+// - repo: the repository name
+//
+// It returns a map of languages (map[string]int, language => num bytes)
+func (g *giteaCrawler) fetchRepositoryLanguages(args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var owner string
+	switch args[0].(type) {
+	case string:
+		owner = args[0].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	var repo string
+	switch args[1].(type) {
+	case string:
+		repo = args[1].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[1]))
+		return nil, errInvalidParamType
+	}
+
+	langs := map[string]int{}
+	path := fmt.Sprintf("repos/%s/%s/languages", url.QueryEscape(owner), url.QueryEscape(repo))
+	if _, err := g.get(path, nil, &langs); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	return langs, nil
+}
+
+// fetchUser fetches information about a user.
+//
+// args expects 1 value:
+// - username: the user login name
+//
+// It returns a *gtUser.
+func (g *giteaCrawler) fetchUser(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var username string
+	switch args[0].(type) {
+	case string:
+		username = args[0].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	user := new(gtUser)
+	if _, err := g.get("users/"+url.QueryEscape(username), nil, user); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// fetchOrganization fetches information about an organization.
+// If there is no such organization, errUnavailable is returned.
+//
+// args expects 1 value:
+// - orgName: the organization name
+//
+// It returns a *gtOrganization.
+func (g *giteaCrawler) fetchOrganization(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var orgName string
+	switch args[0].(type) {
+	case string:
+		orgName = args[0].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	org := new(gtOrganization)
+	if _, err := g.get("orgs/"+url.QueryEscape(orgName), nil, org); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+// fetchOrganizationMembers fetches a page of members of an organization.
+//
+// args expects 2 values:
+// - orgName: the organization name
+// - page: the page number, starting at 1
+//
+// It returns a list of users.
+func (g *giteaCrawler) fetchOrganizationMembers(args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var orgName string
+	switch args[0].(type) {
+	case string:
+		orgName = args[0].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	var page int
+	switch args[1].(type) {
+	case int:
+		page = args[1].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[1]))
+		return nil, errInvalidParamType
+	}
+
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(giteaPageSize))
+	params.Set("page", strconv.Itoa(page))
+
+	var users []gtUser
+	if _, err := g.get("orgs/"+url.QueryEscape(orgName)+"/members", params, &users); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// getGtRepoID returns the gitea repository id of the repository identified by
+// repoID in gt_repositories table.
+// If the repository is not in the table, then 0 is returned. If an error
+// occurs, -1 is returned.
+func (g *giteaCrawler) getGtRepoID(repoID int64) int {
+	var id int
+	err := g.db.QueryRow("SELECT id FROM gt_repositories WHERE repository_id=$1", repoID).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// getGtOrgID returns the gitea organization id of org in gt_organizations
+// table.
+// If org is not in the table, then 0 is returned. If an error occurs, -1 is
+// returned.
+func (g *giteaCrawler) getGtOrgID(org *gtOrganization) int {
+	if org == nil {
+		glog.Error("'org' arg given is nil")
+		return -1
+	}
+
+	var id int
+	err := g.db.QueryRow("SELECT id FROM gt_organizations WHERE instance_url=$1 AND gitea_id=$2",
+		g.instanceURL, org.ID).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// getGtUserID returns the gitea user id of user in gt_users table.
+// If user is not in the table, then 0 is returned. If an error occurs, -1 is
+// returned.
+func (g *giteaCrawler) getGtUserID(user *gtUser) int {
+	if user == nil {
+		glog.Error("'user' arg given is nil")
+		return -1
+	}
+
+	var id int
+	err := g.db.QueryRow("SELECT id FROM gt_users WHERE instance_url=$1 AND gitea_id=$2",
+		g.instanceURL, user.ID).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// getUserID returns the user id of user in users table.
+// If user is not in the table, then 0 is returned. If an error occurs, -1 is
+// returned.
+func (g *giteaCrawler) getUserID(user *gtUser) int {
+	if user == nil {
+		glog.Error("'user' arg given is nil")
+		return -1
+	}
+
+	var id int
+	err := g.db.QueryRow("SELECT user_id FROM gt_users WHERE instance_url=$1 AND gitea_id=$2",
+		g.instanceURL, user.ID).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// insertOrUpdateRepo inserts or updates a repository. It also inserts or
+// updates related Gitea repository, users, Gitea users and Gitea organization
+// (if any).
+func (g *giteaCrawler) insertOrUpdateRepo(repo *gtRepository, lang string) bool {
+	if repo == nil {
+		glog.Error("'repo' arg given is nil")
+		return false
+	}
+	glog.Infof("insert or update repository: %s", *repo.FullName)
+
+	clonePath := strings.ToLower(filepath.Join(lang, g.baseURL.Host, *repo.FullName))
+	repoFields := []string{"name", "primary_language", "clone_url", "clone_path", "vcs"}
+
+	var query string
+	if id := getRepoIDByCloneURL(g.db, repo.CloneURL); id > 0 {
+		query = genUpdateQuery("repositories", id, repoFields...)
+	} else if id == 0 {
+		query = genInsQuery("repositories", repoFields...)
+	} else {
+		return false
+	}
+
+	var repoID int64
+	err := g.db.QueryRow(query+" RETURNING id", repo.Name, lang, repo.CloneURL, clonePath, "git").Scan(&repoID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if !g.insertOrUpdateGtRepo(repoID, repo) {
+		return false
+	}
+
+	tmp, err := g.fetchOrganization(*repo.Owner.Login)
+	switch {
+	case err == errUnavailable:
+		// the owner is a user, not an organization
+		return g.insertOrUpdateUser(*repo.Owner.Login, repoID, 0)
+	case err == errTooManyCall:
+		tmp = g.call(g.fetchOrganization, *repo.Owner.Login)
+	case err != nil:
+		glog.Error(err)
+		return false
+	}
+
+	org, ok := tmp.(*gtOrganization)
+	if !ok {
+		glog.Error("invalid function return type")
+		return false
+	}
+
+	return g.insertOrUpdateGtOrg(org, repoID)
+}
+
+// insertOrUpdateGtRepo inserts, or updates, a gitea repository in the
+// database.
+func (g *giteaCrawler) insertOrUpdateGtRepo(repoID int64, repo *gtRepository) bool {
+	if repo == nil {
+		glog.Error("'repo' arg given is nil")
+		return false
+	}
+	glog.Infof("insert or update gitea repository: %s", *repo.FullName)
+
+	gtRepoFields := []string{
+		"repository_id",
+		"gitea_id",
+		"full_name",
+		"description",
+		"website",
+		"fork",
+		"mirror",
+		"archived",
+		"html_url",
+		"default_branch",
+		"stars_count",
+		"forks_count",
+		"watchers_count",
+		"open_issues_count",
+		"size_in_kb",
+		"created_at",
+		"updated_at",
+	}
+
+	var query string
+	if id := g.getGtRepoID(repoID); id > 0 {
+		query = genUpdateQuery("gt_repositories", id, gtRepoFields...)
+	} else if id == 0 {
+		query = genInsQuery("gt_repositories", gtRepoFields...)
+	} else {
+		return false
+	}
+
+	_, err := g.db.Exec(query,
+		repoID,
+		repo.ID,
+		repo.FullName,
+		repo.Description,
+		repo.Website,
+		repo.Fork,
+		repo.Mirror,
+		repo.Archived,
+		repo.HTMLURL,
+		repo.DefaultBranch,
+		repo.StarsCount,
+		repo.ForksCount,
+		repo.WatchersCount,
+		repo.OpenIssuesCount,
+		repo.Size,
+		formatTime(repo.CreatedAt),
+		formatTime(repo.UpdatedAt))
+
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// insertOrUpdateGtOrg inserts, or updates, a gitea organization into the
+// database. Members of the organization are linked to the given repository.
+func (g *giteaCrawler) insertOrUpdateGtOrg(org *gtOrganization, repoID int64) bool {
+	if org == nil {
+		glog.Error("'org' arg given is nil")
+		return false
+	}
+
+	// older versions of Gitea and Gogs only provide the username field
+	name := org.Name
+	if name == nil {
+		name = org.UserName
+	}
+	if name == nil || org.ID == nil {
+		glog.Error("organization has nil name or ID field")
+		return false
+	}
+	glog.Infof("insert or update gitea organization: %s", *name)
+
+	gtOrgFields := []string{
+		"instance_url",
+		"gitea_id",
+		"name",
+		"full_name",
+		"description",
+		"website",
+		"location",
+		"avatar_url",
+	}
+
+	var query string
+	if id := g.getGtOrgID(org); id > 0 {
+		query = genUpdateQuery("gt_organizations", id, gtOrgFields...)
+	} else if id == 0 {
+		query = genInsQuery("gt_organizations", gtOrgFields...)
+	} else {
+		return false
+	}
+
+	var orgID int64
+	err := g.db.QueryRow(query+" RETURNING id",
+		g.instanceURL,
+		org.ID,
+		name,
+		org.FullName,
+		org.Description,
+		org.Website,
+		org.Location,
+		org.AvatarURL).Scan(&orgID)
+
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	for page := 1; ; page++ {
+		tmp := g.call(g.fetchOrganizationMembers, *name, page)
+		users, ok := tmp.([]gtUser)
+		if !ok {
+			glog.Error("invalid function return type")
+			return false
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		for _, user := range users {
+			if user.Login == nil {
+				glog.Error("'user' has nil Login field")
+				continue
+			}
+			if !g.insertOrUpdateUser(*user.Login, repoID, orgID) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// insertOrUpdateUser inserts, or updates, a gitea user into the database.
+func (g *giteaCrawler) insertOrUpdateUser(username string, repoID int64, orgID int64) bool {
+	glog.Infof("insert or update user: %s", username)
+
+	if repoID <= 0 {
+		glog.Error("trying to insert a user without linked Gitea repository")
+		return false
+	}
+
+	tmp := g.call(g.fetchUser, username)
+	var user *gtUser
+	switch tmp.(type) {
+	case *gtUser:
+		user = tmp.(*gtUser)
+	default:
+		glog.Error("invalid function return type")
+		return false
+	}
+
+	userFields := []string{"username", "name", "email"}
+
+	var query string
+	if id := g.getUserID(user); id > 0 {
+		query = genUpdateQuery("users", id, userFields...)
+	} else if id == 0 {
+		query = genInsQuery("users", userFields...)
+	} else {
+		return false
+	}
+
+	var userID int64
+	err := g.db.QueryRow(query+" RETURNING id", user.Login, user.FullName, user.Email).Scan(&userID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if !linkUserToRepo(g.db, userID, repoID) {
+		return false
+	}
+
+	if !g.insertOrUpdateGtUser(userID, user, orgID) {
+		return false
+	}
+
+	return true
+}
+
+// insertOrUpdateGtUser inserts, or updates, a gitea user into the database.
+func (g *giteaCrawler) insertOrUpdateGtUser(userID int64, user *gtUser, orgID int64) bool {
+	if user == nil {
+		glog.Error("'user' arg given is nil")
+		return false
+	}
+	glog.Infof("insert or update gitea user: %s", *user.Login)
+
+	if userID <= 0 {
+		glog.Error("trying to insert a gitea user but no user ID given")
+		return false
+	}
+
+	gtUserFields := []string{
+		"user_id",
+		"instance_url",
+		"gitea_id",
+		"login",
+		"full_name",
+		"email",
+		"avatar_url",
+		"location",
+		"website",
+		"description",
+		"followers_count",
+		"following_count",
+		"created_at",
+	}
+
+	var query string
+	if id := g.getGtUserID(user); id > 0 {
+		query = genUpdateQuery("gt_users", id, gtUserFields...)
+	} else if id == 0 {
+		query = genInsQuery("gt_users", gtUserFields...)
+	} else {
+		return false
+	}
+
+	var gtUserID int64
+	err := g.db.QueryRow(query+" RETURNING id",
+		userID,
+		g.instanceURL,
+		user.ID,
+		user.Login,
+		user.FullName,
+		user.Email,
+		user.AvatarURL,
+		user.Location,
+		user.Website,
+		user.Description,
+		user.FollowersCount,
+		user.FollowingCount,
+		formatTime(user.Created)).Scan(&gtUserID)
+
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if orgID != 0 {
+		if !g.linkGtUserToGtOrg(gtUserID, orgID) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isGtUserLinkedToGtOrg checks whether a gitea user is linked to the given
+// gitea organization or not.
+func (g *giteaCrawler) isGtUserLinkedToGtOrg(gtUserID, orgID int64) bool {
+	row := g.db.QueryRow(
+		`SELECT COUNT(*) AS total
+		 FROM gt_users_organizations
+		 WHERE gt_user_id = $1 AND gt_organization_id = $2`, gtUserID, orgID)
+
+	var total int64
+	if err := row.Scan(&total); err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return total > 0
+}
+
+// linkGtUserToGtOrg links a gitea user to the given gitea organization.
+func (g *giteaCrawler) linkGtUserToGtOrg(gtUserID, orgID int64) bool {
+	if g.isGtUserLinkedToGtOrg(gtUserID, orgID) {
+		return true
+	}
+
+	fields := []string{"gt_user_id", "gt_organization_id"}
+
+	query := genInsQuery("gt_users_organizations", fields...)
+
+	_, err := g.db.Exec(query, gtUserID, orgID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// primaryLanguageBytes returns the language with the biggest number of bytes
+// in langs.
+func primaryLanguageBytes(langs map[string]int) string {
+	shares := make(map[string]float64, len(langs))
+	for k, v := range langs {
+		shares[k] = float64(v)
+	}
+	return primaryLanguage(shares)
+}
+
+// verifyGtRepo checks all essential fields of a gtRepository structure for
+// nil values. An error is returned if one of the essential field is nil.
+func verifyGtRepo(repo *gtRepository) error {
+	if repo == nil {
+		return newInvalidStructError("verifyGtRepo: repo is nil")
+	}
+
+	var err *invalidStructError
+	if repo.ID == nil {
+		err = newInvalidStructError("verifyGtRepo: contains nil fields:").AddField("ID")
+	} else {
+		err = newInvalidStructError(fmt.Sprintf("verifyGtRepo: repo #%d contains nil fields: ", *repo.ID))
+	}
+
+	if repo.Name == nil {
+		err.AddField("Name")
+	}
+
+	if repo.FullName == nil {
+		err.AddField("FullName")
+	}
+
+	if repo.CloneURL == nil {
+		err.AddField("CloneURL")
+	}
+
+	if repo.Owner == nil {
+		err.AddField("Owner")
+	} else {
+		if repo.Owner.Login == nil {
+			err.AddField("Owner.Login")
+		}
+	}
+
+	if repo.Fork == nil {
+		err.AddField("Fork")
+	}
+
+	if err.FieldsLen() > 0 {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/DevMine/crawld/config"
+)
+
+// newTestGiteaCrawler creates a Gitea crawler that queries the stub server
+// located at baseURL. It has no database session.
+func newTestGiteaCrawler(t *testing.T, baseURL string) *giteaCrawler {
+	cfg := config.CrawlerConfig{Type: "gitea", Languages: []string{"go"}, BaseURL: baseURL}
+
+	client, err := newGiteaClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &giteaCrawler{CrawlerConfig: cfg, restClient: client, instanceURL: baseURL}
+}
+
+func TestGiteaFetchRepositories(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/search" {
+			http.NotFound(w, r)
+			return
+		}
+		if page := r.URL.Query().Get("page"); page != "2" {
+			t.Errorf("page: expected '2', found '%s'", page)
+		}
+		fmt.Fprint(w, `{"ok": true, "data": [{
+			"id": 42,
+			"name": "crawld",
+			"full_name": "devmine/crawld",
+			"fork": false,
+			"clone_url": "https://gitea.example.com/devmine/crawld.git",
+			"owner": {"id": 7, "login": "devmine"}
+		}]}`)
+	}))
+	defer ts.Close()
+
+	g := newTestGiteaCrawler(t, ts.URL)
+
+	repos, ok := g.call(g.fetchRepositories, 2).([]gtRepository)
+	if !ok {
+		t.Fatal("fetchRepositories: expected a list of repositories")
+	}
+
+	if len(repos) != 1 {
+		t.Fatalf("len(repos): expected 1, found %d", len(repos))
+	}
+
+	if err := verifyGtRepo(&repos[0]); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGiteaFetchOrganization(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/orgs/devmine" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"id": 7, "username": "devmine"}`)
+	}))
+	defer ts.Close()
+
+	g := newTestGiteaCrawler(t, ts.URL)
+
+	if _, err := g.fetchOrganization("alice"); err != errUnavailable {
+		t.Errorf("fetchOrganization(alice): expected errUnavailable, found %v", err)
+	}
+
+	tmp, err := g.fetchOrganization("devmine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if org := tmp.(*gtOrganization); *org.UserName != "devmine" {
+		t.Errorf("username: expected 'devmine', found '%s'", *org.UserName)
+	}
+}
+
+func TestNewGiteaClientRequiresBaseURL(t *testing.T) {
+	if _, err := newGiteaClient(config.CrawlerConfig{Type: "gogs"}); err == nil {
+		t.Error("newGiteaClient: expected an error when no base url is given")
+	}
+}
+
+func TestGiteaStartPage(t *testing.T) {
+	// 10 pages of repositories whose IDs are even
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		var repos []gtRepository
+		for i := (page - 1) * giteaPageSize; i < page*giteaPageSize && i < 10*giteaPageSize; i++ {
+			id := 2 * (i + 1)
+			repos = append(repos, gtRepository{ID: &id})
+		}
+		if err := json.NewEncoder(w).Encode(gtSearchResults{Data: repos}); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	g := newTestGiteaCrawler(t, ts.URL)
+
+	tests := []struct {
+		sinceID int
+		page    int
+	}{
+		{0, 1},
+		{99, 1},
+		{100, 2},
+		{501, 6},
+		{1000, 11},
+	}
+	for _, tt := range tests {
+		calls = 0
+		if page := g.startPage(tt.sinceID); page != tt.page {
+			t.Errorf("startPage(%d): expected page %d, found %d", tt.sinceID, tt.page, page)
+		}
+		if calls > 8 {
+			t.Errorf("startPage(%d): expected a bisection, found %d requests", tt.sinceID, calls)
+		}
+	}
+}
@@ -7,16 +7,17 @@ package crawlers
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/golang/glog"
 	"github.com/google/go-github/github"
-	"golang.org/x/net/context"
-	"golang.org/x/oauth2"
 
 	"github.com/DevMine/crawld/config"
 )
@@ -32,22 +33,64 @@ type gitHubCrawler struct {
 	config.CrawlerConfig
 
 	client *github.Client
+	tokens *tokenPool
+	etags  *etagCache
 	db     *sql.DB
 }
 
 // ensure that gitHubCrawler implements the Crawler interface
 var _ Crawler = (*gitHubCrawler)(nil)
 
-// implement the oauth2.TokenSource interface
-type tokenSource struct {
-	AccessToken string
+// ghRepository is a GitHub repository, with the fields that
+// github.Repository lacks.
+type ghRepository struct {
+	github.Repository
+
+	Topics     []string   `json:"topics,omitempty"`
+	License    *ghLicense `json:"license,omitempty"`
+	Archived   *bool      `json:"archived,omitempty"`
+	Disabled   *bool      `json:"disabled,omitempty"`
+	IsTemplate *bool      `json:"is_template,omitempty"`
+	Visibility *string    `json:"visibility,omitempty"`
 }
 
-func (ts *tokenSource) Token() (*oauth2.Token, error) {
-	token := &oauth2.Token{
-		AccessToken: ts.AccessToken,
-	}
-	return token, nil
+// ghLicense is the license of a GitHub repository.
+type ghLicense struct {
+	Key    *string `json:"key,omitempty"`
+	SPDXID *string `json:"spdx_id,omitempty"`
+}
+
+// ghSearchResults are the results of a GitHub repositories search query.
+type ghSearchResults struct {
+	Total *int           `json:"total_count,omitempty"`
+	Items []ghRepository `json:"items,omitempty"`
+}
+
+// ghRepositoriesPage is a page of GitHub repositories search results.
+type ghRepositoriesPage struct {
+	repos []ghRepository
+
+	// total is the total number of repositories matching the search query.
+	total int
+
+	// nextPage is the number of the next page, or 0 if this is the last one.
+	nextPage int
+}
+
+// ghMembersPage is a page of members of a GitHub organization.
+type ghMembersPage struct {
+	users []github.User
+
+	// nextPage is the number of the next page, or 0 if this is the last one.
+	nextPage int
+}
+
+// ghContributorsPage is a page of contributors of a GitHub repository.
+type ghContributorsPage struct {
+	contributors []github.Contributor
+
+	// nextPage is the number of the next page, or 0 if this is the last one.
+	nextPage int
 }
 
 // newGitHubCrawler creates a new GitHub crawler.
@@ -56,91 +99,145 @@ func newGitHubCrawler(cfg config.CrawlerConfig, db *sql.DB) (*gitHubCrawler, err
 		return nil, errors.New("database session cannot be nil")
 	}
 
-	var httpClient *http.Client
-	if len(strings.Trim(cfg.OAuthAccessToken, " ")) != 0 {
-		ts := &tokenSource{
-			AccessToken: cfg.OAuthAccessToken,
-		}
-		httpClient = oauth2.NewClient(context.TODO(), ts)
+	tokens := newTokenPool(append([]string{cfg.OAuthAccessToken}, cfg.OAuthAccessTokens...))
+	client := github.NewClient(&http.Client{Transport: tokens})
+
+	var etags *etagCache
+	if cfg.UseETags {
+		etags = newETagCache(db)
 	}
-	client := github.NewClient(httpClient)
 
-	return &gitHubCrawler{cfg, client, db}, nil
+	return &gitHubCrawler{cfg, client, tokens, etags, db}, nil
 }
 
 // Crawl implements the Crawl() method of the Crawler interface.
 func (g *gitHubCrawler) Crawl() {
+	if g.RevalidateRepos {
+		g.revalidateRepositories()
+	}
+
 	if g.UseSearchAPI {
 		for _, lang := range g.Languages {
-			_ = g.call(true, g.fetchTopRepositories, lang)
+			g.crawlTopRepositories(lang)
 		}
 	} else {
-		_ = g.call(false, g.fetchRepositories)
+		g.crawlRepositories()
 	}
 }
 
 // call shall be used when doing a query on the GitHub API. If the query is
 // refused, typically because the rate limit is reached, then this function
-// waits for the appropriate time before retrying the query.
+// retries the query with another token or, if all tokens are exhausted, waits
+// for the appropriate time before retrying the query.
 // isSearchRequest shall be used to indicate if apiCallFunc calls the search API
 // (rate limit for the search API differ from the core API).
 func (g *gitHubCrawler) call(isSearchRequest bool, fct apiCallFunc, args ...interface{}) interface{} {
+	ret, _ := g.callErr(isSearchRequest, fct, args...)
+	return ret
+}
+
+// callErr is like call but it also returns the error of the query, which is
+// typically used to tell whether a resource has been modified or not.
+func (g *gitHubCrawler) callErr(isSearchRequest bool, fct apiCallFunc, args ...interface{}) (interface{}, error) {
+	resource := coreResource
+	if isSearchRequest {
+		resource = searchResource
+	}
+	return g.callResource(resource, fct, args...)
+}
+
+// callGraphQL is like call for functions that query the GraphQL API, whose
+// rate limit is expressed in points.
+func (g *gitHubCrawler) callGraphQL(fct apiCallFunc, args ...interface{}) interface{} {
+	ret, _ := g.callResource(graphqlResource, fct, args...)
+	return ret
+}
+
+// callResource does the actual work of call and callGraphQL. resource is the
+// rate limit resource apiCallFunc is accounted to.
+func (g *gitHubCrawler) callResource(resource string, fct apiCallFunc, args ...interface{}) (interface{}, error) {
 	var ret interface{}
 	var err error
 
 	// gotta wait if rate limit is exceeded
-	for {
+	for switches := 0; ; {
 		if ret, err = fct(args...); err != errTooManyCall {
 			break
 		}
 
-		var reset int64
-		limits, _, _ := g.client.RateLimits()
-		if isSearchRequest {
-			reset = limits.Search.Reset.Unix()
-		} else {
-			reset = limits.Core.Reset.Unix()
+		// do not switch tokens indefinitely if the pool is out of sync
+		if switches < len(g.tokens.tokens) && g.tokens.available(resource) {
+			glog.Info("API rate limit exceeded => switching to another token")
+			switches++
+			continue
+		}
+		switches = 0
+
+		waitTime := g.tokens.nextReset(resource).Unix() - time.Now().Unix() + 1
+		if waitTime <= 0 {
+			waitTime = 1
 		}
-		waitTime := reset - time.Now().Unix() + 1
 		glog.Infof("not enough API calls left => waiting for %d minutes and %d seconds",
 			waitTime/60, waitTime%60)
 		time.Sleep(time.Duration(waitTime) * time.Second)
 	}
 
-	return ret
+	return ret, err
 }
 
-// fetchRepositories fetches N GitHub repositories in the given
-// language (if provided).
+// wantedRepoLanguages tells whether repo is written in one of the languages
+// of interest. Its languages are only fetched when its primary language is
+// not one of them, in which case they are returned.
+func (g *gitHubCrawler) wantedRepoLanguages(repo *github.Repository) (map[string]int, bool) {
+	if ok, err := isLanguageWanted(g.Languages, repo.Language); err != nil {
+		glog.Error(err)
+		return nil, false
+	} else if ok {
+		return nil, true
+	}
+
+	tmp := g.call(false, g.fetchRepositoryLanguages, *repo.Owner.Login, *repo.Name)
+	if ok, err := isLanguageWanted(g.Languages, tmp); err != nil {
+		glog.Error(err)
+		return nil, false
+	} else if !ok {
+		return nil, false
+	}
+
+	langs, _ := tmp.(map[string]int)
+	return langs, true
+}
+
+// crawlRepositories crawls N GitHub repositories in the languages of interest
+// (if provided). The limit N is global to all languages.
 //
 // Warning: This method does not use the search API, thus, it uses a lot of API
 // calls.
-//
-// args expects no argument.
-//
-// TODO add doc => the limit N is global to all languages
-func (g *gitHubCrawler) fetchRepositories(args ...interface{}) (interface{}, error) {
-	if len(args) != 0 {
-		glog.Error("invalid number of arguments")
-		return nil, errInvalidArgs
-	}
-
+func (g *gitHubCrawler) crawlRepositories() {
 	n := g.Limit
 
 	keepFork := g.Fork
 	hasLimit := n > 0
 
-	// GitHub lists repositories 100 per page, regardless of the per_page option...
-	opt := &github.RepositoryListAllOptions{}
-
 	sinceID := g.SinceID
+	if cursor := getCursor(g.db, g.Name); cursor > sinceID {
+		glog.Infof("%s crawler: resuming from repository ID %d", g.Name, cursor)
+		sinceID = cursor
+	}
+
+	// repositories to fetch with the GraphQL API
+	var batch []github.Repository
+
 ResultsLoop:
 	for {
-		opt.Since = sinceID
-		repos, resp, err := g.client.Repositories.ListAll(opt)
-		if err != nil {
-			glog.Error(err)
-			return nil, g.genAPICallFuncError(resp, err)
+		tmp := g.call(false, g.fetchRepositories, sinceID)
+		var repos []github.Repository
+		switch tmp.(type) {
+		case []github.Repository:
+			repos = tmp.([]github.Repository)
+		default:
+			glog.Error("invalid function return type")
+			return
 		}
 
 		if len(repos) == 0 {
@@ -148,16 +245,16 @@ ResultsLoop:
 		}
 
 		for _, repo := range repos {
+			if n == 0 && hasLimit {
+				break ResultsLoop
+			}
+
 			if repo.ID == nil {
 				glog.Error("'repo' has nil ID field")
 				continue
 			}
 			sinceID = *repo.ID
 
-			if n == 0 && hasLimit {
-				break ResultsLoop
-			}
-
 			if repo.Fork == nil {
 				glog.Error("'repo' has nil Fork field")
 				continue
@@ -167,27 +264,56 @@ ResultsLoop:
 				continue
 			}
 
-			if ok, err := isLanguageWanted(g.Languages, repo.Language); err != nil {
-				glog.Error(err)
+			// languages are kept to be saved along with the repository
+			langs, ok := g.wantedRepoLanguages(&repo)
+			if !ok {
 				continue
-			} else if !ok {
-				langs := g.call(false, g.fetchRepositoryLanguages, *repo.Owner.Login, *repo.Name)
+			}
 
-				if ok, err := isLanguageWanted(g.Languages, langs); err != nil {
-					glog.Error(err)
+			// GraphQL queries are too costly to be spent on repositories
+			// whose languages are not of interest
+			if g.UseGraphQL {
+				batch = append(batch, repo)
+				if len(batch) == graphQLBatchSize || (hasLimit && int64(len(batch)) == n) {
+					g.crawlGraphQLBatch(batch, &n)
+					batch = batch[:0]
+				}
+				continue
+			}
+
+			repoURL := ghRepoURL(*repo.Owner.Login, *repo.Name)
+			tmpRepo, err := g.callErr(false, g.fetchRepository, *repo.Owner.Login, *repo.Name)
+			if err == errNotModified {
+				if id := g.getRepoID(&repo); id > 0 {
+					glog.Infof("repository %s not modified since the last crawl", repoURL)
+					// the languages are not covered by the ETag of the
+					// repository
+					if langs != nil {
+						setRepoLanguages(g.db, int64(id), langs)
+					}
+					n--
 					continue
-				} else if !ok {
+				} else if id < 0 {
 					continue
 				}
+
+				// the repository is not in the database anymore
+				g.etags.forget(repoURL)
+				tmpRepo = g.call(false, g.fetchRepository, *repo.Owner.Login, *repo.Name)
+			}
+			if err == errGone {
+				// the repository has been deleted since it was listed
+				if id := g.getRepoID(&repo); id > 0 {
+					g.markRepoGone(int64(id))
+				}
+				continue
 			}
 
-			var fullRepo *github.Repository
-			tmpRepo := g.call(false, g.fetchRepository, *repo.Owner.Login, *repo.Name)
+			var fullRepo *ghRepository
 			switch tmpRepo.(type) {
-			case *github.Repository:
-				fullRepo = tmpRepo.(*github.Repository)
-				err = verifyRepo(fullRepo)
-				if err != nil {
+			case *ghRepository:
+				fullRepo = tmpRepo.(*ghRepository)
+				if err := verifyRepo(&fullRepo.Repository); err != nil {
 					glog.Error(err)
 					continue
 				}
@@ -198,96 +324,248 @@ ResultsLoop:
 
 			// skip when an the method fail because the repository is not
 			// saved into the DB
-			if !g.insertOrUpdateRepo(fullRepo) {
+			if !g.insertOrUpdateRepo(fullRepo, langs) {
 				continue
 			}
+			g.etags.commit(repoURL)
+			g.crawlForks(fullRepo, g.ForkDepth)
 
 			n--
 		}
 
+		g.crawlGraphQLBatch(batch, &n)
+		batch = batch[:0]
+
+		// persist the progress so that the next crawl, or a restart,
+		// resumes from here
+		saveCursor(g.db, g.Name, sinceID)
+
 		if n <= 0 && hasLimit {
 			break
 		}
 	}
-	return nil, nil
+
+	saveCursor(g.db, g.Name, sinceID)
 }
 
-// fetchTopRepositories fetches top N GitHub repositories in the given
-// language (if provided).
+// crawlTopRepositories crawls top N GitHub repositories in the given
+// language. The limit N is for the language separately.
 //
 // Warning: This method uses the search API, thus it cannot fetch more than
-// 1000 results.
-//
-// args expects 1 values:
-//   - language: string indicating the programming language to limit the fetch
+// 1000 results, unless search date slicing is enabled.
 // Be very careful if you do not specify a limit and/or a programming language.
-//
-// TODO add doc => the limit N is for language separately
-func (g *gitHubCrawler) fetchTopRepositories(args ...interface{}) (interface{}, error) {
-	if len(args) != 1 {
-		glog.Error("invalid number of arguments")
-		return nil, errInvalidArgs
-	}
-
+func (g *gitHubCrawler) crawlTopRepositories(lang string) {
 	n := g.Limit
+	seen := map[int]bool{}
 
-	var lang string
-	switch args[0].(type) {
-	case string:
-		lang = args[0].(string)
-	default:
-		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
-		return nil, errInvalidParamType
+	if g.SearchDateSlicing {
+		g.crawlSearchSlice(newSearchSlice(lang), &n, seen)
+		return
 	}
 
+	query := g.searchQuery("language:" + lang)
+	if results, ok := g.searchRepositories(query, 1); ok {
+		g.crawlSearchResults(query, results, &n, seen)
+	}
+}
+
+// crawlSearchResults crawls the repositories matching a search query,
+// starting from the given page of results. n is the number of repositories
+// left to crawl, which is ignored when the crawler has no limit. seen is the
+// set of GitHub IDs of the repositories already crawled, which are skipped.
+// It returns false once the limit is reached.
+func (g *gitHubCrawler) crawlSearchResults(query string, results ghRepositoriesPage, n *int64, seen map[int]bool) bool {
 	keepFork := g.Fork
-	hasLimit := n > 0
+	hasLimit := g.Limit > 0
 
-	opt := &github.SearchOptions{Sort: "stars", ListOptions: github.ListOptions{PerPage: 100}}
+	// repositories to fetch with the GraphQL API
+	var batch []github.Repository
 
-ResultsLoop:
 	for {
-		results, resp, err := g.client.Search.Repositories(
-			"language:"+lang, opt)
-		if err != nil {
-			glog.Error(err)
-			return nil, g.genAPICallFuncError(resp, err)
-		}
-
-		repos := results.Repositories
-
-		for _, repo := range repos {
-			if n == 0 && hasLimit {
-				break ResultsLoop
+		for _, repo := range results.repos {
+			if *n == 0 && hasLimit {
+				return false
 			}
 
-			err = verifyRepo(&repo)
-			if err != nil {
+			if err := verifyRepo(&repo.Repository); err != nil {
 				glog.Error(err)
 				continue
 			}
 
+			// skip already crawled repos, search results may overlap
+			if seen[*repo.ID] {
+				continue
+			}
+			seen[*repo.ID] = true
+
 			// skip? fork repos
 			if *repo.Fork && !keepFork {
 				continue
 			}
 
+			if g.UseGraphQL {
+				if _, ok := g.wantedRepoLanguages(&repo.Repository); !ok {
+					continue
+				}
+
+				batch = append(batch, repo.Repository)
+				if len(batch) == graphQLBatchSize || (hasLimit && int64(len(batch)) == *n) {
+					g.crawlGraphQLBatch(batch, n)
+					batch = batch[:0]
+				}
+				continue
+			}
+
 			// skip when an the method fail because the repository is not
 			// saved into the DB
-			if !g.insertOrUpdateRepo(&repo) {
+			if !g.insertOrUpdateRepo(&repo, nil) {
 				continue
 			}
+			g.crawlForks(&repo, g.ForkDepth)
 
-			n--
+			*n--
 		}
 
-		if resp.NextPage == 0 || (n <= 0 && hasLimit) {
-			break
+		g.crawlGraphQLBatch(batch, n)
+		batch = batch[:0]
+
+		if *n <= 0 && hasLimit {
+			return false
+		}
+
+		if results.nextPage == 0 {
+			return true
 		}
 
-		opt.Page = resp.NextPage
+		var ok bool
+		if results, ok = g.searchRepositories(query, results.nextPage); !ok {
+			return true
+		}
 	}
-	return nil, nil
+}
+
+// searchQuery appends the search qualifiers of the crawler configuration to
+// the given search query.
+func (g *gitHubCrawler) searchQuery(query string) string {
+	if len(g.SearchQualifiers) == 0 {
+		return query
+	}
+	return query + " " + strings.Join(g.SearchQualifiers, " ")
+}
+
+// searchRepositories returns a page of the results of a repositories search
+// query. The boolean is false if the results could not be fetched.
+func (g *gitHubCrawler) searchRepositories(query string, page int) (ghRepositoriesPage, bool) {
+	tmp := g.call(true, g.fetchTopRepositories, query, page)
+	switch tmp.(type) {
+	case ghRepositoriesPage:
+		return tmp.(ghRepositoriesPage), true
+	default:
+		glog.Error("invalid function return type")
+		return ghRepositoriesPage{}, false
+	}
+}
+
+// fetchRepositories fetches a page of GitHub repositories, in the order in
+// which they were created. GitHub lists repositories 100 per page, regardless
+// of the per_page option.
+//
+// args expects 1 value:
+// - sinceID: only repositories with an ID greater than sinceID are listed
+//
+// It returns a list of repositories.
+func (g *gitHubCrawler) fetchRepositories(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var sinceID int
+	switch args[0].(type) {
+	case int:
+		sinceID = args[0].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	opt := &github.RepositoryListAllOptions{Since: sinceID}
+	repos, resp, err := g.client.Repositories.ListAll(opt)
+	if err != nil {
+		glog.Error(err)
+		return nil, g.genAPICallFuncError(resp, err)
+	}
+
+	return repos, nil
+}
+
+// fetchTopRepositories fetches a page of the most popular GitHub repositories
+// matching a search query (eg: "language:go"), using the search API.
+//
+// args expects 2 values:
+// - query: the search query
+// - page: the page number, starting at 1
+//
+// It returns a ghRepositoriesPage.
+func (g *gitHubCrawler) fetchTopRepositories(args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var query string
+	switch args[0].(type) {
+	case string:
+		query = args[0].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	var page int
+	switch args[1].(type) {
+	case int:
+		page = args[1].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[1]))
+		return nil, errInvalidParamType
+	}
+
+	sort := g.SearchSort
+	if len(sort) == 0 {
+		sort = "stars"
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("sort", sort)
+	if len(g.SearchOrder) != 0 {
+		params.Set("order", g.SearchOrder)
+	}
+	params.Set("per_page", "100")
+	params.Set("page", strconv.Itoa(page))
+
+	// results are not fetched with g.client.Search since github.Repository
+	// lacks some of the fields of the results
+	req, err := g.client.NewRequest("GET", "search/repositories?"+params.Encode(), nil)
+	if err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	results := new(ghSearchResults)
+	resp, err := g.client.Do(req, results)
+	if err != nil {
+		glog.Error(err)
+		return nil, g.genAPICallFuncError(resp, err)
+	}
+
+	var total int
+	if results.Total != nil {
+		total = *results.Total
+	}
+
+	return ghRepositoriesPage{repos: results.Items, total: total, nextPage: resp.NextPage}, nil
 }
 
 // fetchRepositoryLanguages fetches all languages related to a repository
@@ -335,7 +613,7 @@ func (g *gitHubCrawler) fetchRepositoryLanguages(args ...interface{}) (interface
 // - owner: the repository owner
 // - rpeo: the repository name
 //
-// It returns a github.Repository
+// It returns a *ghRepository
 func (g *gitHubCrawler) fetchRepository(args ...interface{}) (interface{}, error) {
 	if len(args) != 2 {
 		glog.Error("invalid number of arguments")
@@ -360,9 +638,19 @@ func (g *gitHubCrawler) fetchRepository(args ...interface{}) (interface{}, error
 		return nil, errInvalidParamType
 	}
 
-	ghRepo, resp, err := g.client.Repositories.Get(owner, repo)
+	ghRepo := new(ghRepository)
+	resp, err := g.getConditional(ghRepoURL(owner, repo), ghRepo)
 	if err != nil {
-		glog.Error(err)
+		if err != errNotModified {
+			glog.Error(err)
+		}
+
+		// renamed and transferred repositories are answered with a
+		// redirection, which the http client follows
+		if resp != nil && (resp.StatusCode == http.StatusNotFound ||
+			resp.StatusCode == http.StatusUnavailableForLegalReasons) {
+			return nil, errGone
+		}
 		return nil, g.genAPICallFuncError(resp, err)
 	}
 
@@ -429,6 +717,23 @@ func (g *gitHubCrawler) getGhOrgID(org *github.Organization) int {
 	return id
 }
 
+// getGhOrgIDByLogin returns the github organization id of the organization
+// identified by login in gh_organizations table.
+// If the organization is not in the table, then 0 is returned. If an error
+// occurs, -1 is returned.
+func (g *gitHubCrawler) getGhOrgIDByLogin(login string) int {
+	var id int
+	err := g.db.QueryRow("SELECT id FROM gh_organizations WHERE login=$1", login).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
 // getGhUserID returns the github user id of user in gh_users table.
 // If user not in the table, then 0 is returned. If an error occurs, -1 is returned.
 func (g *gitHubCrawler) getGhUserID(user *github.User) int {
@@ -470,31 +775,35 @@ func (g *gitHubCrawler) getUserID(user *github.User) int {
 }
 
 // insertOrUpdateRepo inserts or updates a repository. It also inserts or
-// updates related GitHub repository, users, GitHub users and GitHub
-// organization (if any).
-func (g *gitHubCrawler) insertOrUpdateRepo(repo *github.Repository) bool {
+// updates related GitHub repository, languages, users, GitHub users and
+// GitHub organization (if any). langs are the languages of the repository.
+// If nil, they are only fetched when they may have changed since the last
+// crawl.
+func (g *gitHubCrawler) insertOrUpdateRepo(repo *ghRepository, langs map[string]int) bool {
 	if repo == nil {
 		glog.Error("'repo' arg given is nil")
 		return false
 	}
 	glog.Infof("insert or update repository: %s", *repo.Name)
 
-	clonePath := strings.ToLower(filepath.Join(*repo.Language, *repo.Owner.Login, *repo.Name))
-	repoFields := []string{"name", "primary_language", "clone_url", "clone_path", "vcs"}
-
-	var query string
-	if id := g.getRepoID(repo); id > 0 {
-		query = genUpdateQuery("repositories", id, repoFields...)
-	} else if id == 0 {
-		query = genInsQuery("repositories", repoFields...)
-	} else {
+	repoID := g.saveRepo(&repo.Repository)
+	if repoID <= 0 {
 		return false
 	}
 
-	var repoID int64
-	err := g.db.QueryRow(query+" RETURNING id", repo.Name, repo.Language, repo.CloneURL, clonePath, "git").Scan(&repoID)
-	if err != nil {
-		glog.Error(err)
+	// the languages only change when commits are pushed
+	if langs == nil && g.languagesOutdated(&repo.Repository) {
+		tmp := g.call(false, g.fetchRepositoryLanguages, *repo.Owner.Login, *repo.Name)
+		switch tmp.(type) {
+		case map[string]int:
+			langs = tmp.(map[string]int)
+		default:
+			glog.Error("invalid function return type")
+			return false
+		}
+	}
+
+	if langs != nil && !setRepoLanguages(g.db, repoID, langs) {
 		return false
 	}
 
@@ -512,12 +821,121 @@ func (g *gitHubCrawler) insertOrUpdateRepo(repo *github.Repository) bool {
 		return false
 	}
 
+	if g.CrawlContributors {
+		if !g.insertOrUpdateContributors(*repo.Owner.Login, *repo.Name, repoID) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// languagesOutdated tells whether the languages breakdown of repo has to be
+// fetched, ie whether repo is not in gh_repositories table yet, has no
+// languages or has been pushed to since it was last crawled.
+func (g *gitHubCrawler) languagesOutdated(repo *github.Repository) bool {
+	var pushedAt *time.Time
+	var hasLangs bool
+	err := g.db.QueryRow(
+		`SELECT gh.pushed_at,
+		        EXISTS(SELECT 1 FROM repository_languages WHERE repository_id = gh.repository_id)
+		 FROM gh_repositories gh
+		 WHERE gh.github_id=$1`, repo.ID).Scan(&pushedAt, &hasLangs)
+	switch {
+	case err == sql.ErrNoRows:
+		return true
+	case err != nil:
+		glog.Error(err)
+		return true
+	}
+
+	if !hasLangs || pushedAt == nil || repo.PushedAt == nil {
+		return true
+	}
+	return !pushedAt.Equal(repo.PushedAt.Time)
+}
+
+// saveRepo inserts, or updates, a repository into the repositories table. It
+// returns the id of the repository, or -1 if an error occurs.
+func (g *gitHubCrawler) saveRepo(repo *github.Repository) int64 {
+	clonePath := ghClonePath(repo)
+	repoFields := []string{"name", "primary_language", "clone_url", "clone_path", "vcs", "gone_at"}
+
+	var query string
+	if id := g.getRepoID(repo); id > 0 {
+		// the repository has been renamed, transferred, or its language has
+		// changed: the fetcher moves its clone from the previous clone path
+		_, err := g.db.Exec(
+			`UPDATE repositories
+			 SET previous_clone_path = COALESCE(previous_clone_path, clone_path)
+			 WHERE id = $1 AND clone_path <> $2`, id, clonePath)
+		if err != nil {
+			glog.Error(err)
+			return -1
+		}
+
+		query = genUpdateQuery("repositories", id, repoFields...)
+	} else if id == 0 {
+		query = genInsQuery("repositories", repoFields...)
+	} else {
+		return -1
+	}
+
+	// gone_at is reset since a repository that can be fetched is not gone
+	var repoID int64
+	err := g.db.QueryRow(query+" RETURNING id", repo.Name, repo.Language, repo.CloneURL, clonePath, "git", nil).Scan(&repoID)
+	if err != nil {
+		glog.Error(err)
+		return -1
+	}
+
+	return repoID
+}
+
+// insertOrUpdateContributors inserts, or updates, all the contributors of a
+// repository into the database, along with their number of contributions to
+// the repository.
+func (g *gitHubCrawler) insertOrUpdateContributors(owner, repoName string, repoID int64) bool {
+	for page := 1; page != 0; {
+		tmp := g.call(false, g.fetchContributors, owner, repoName, page)
+		var contributors ghContributorsPage
+		switch tmp.(type) {
+		case ghContributorsPage:
+			contributors = tmp.(ghContributorsPage)
+		default:
+			glog.Error("invalid function return type")
+			return false
+		}
+
+		for _, c := range contributors.contributors {
+			if c.Login == nil || c.ID == nil || c.Contributions == nil {
+				glog.Error("'contributor' has nil Login, ID or Contributions field")
+				continue
+			}
+
+			if !g.insertOrUpdateUser(c.Login, repoID, 0) {
+				return false
+			}
+
+			userID := g.getUserID(&github.User{ID: c.ID})
+			if userID <= 0 {
+				return false
+			}
+
+			if !setUserRepoContributions(g.db, int64(userID), repoID, *c.Contributions) {
+				return false
+			}
+		}
+
+		page = contributors.nextPage
+	}
+
 	return true
 }
 
 // insertOrUpdateGhRepo inserts, or updates, a github repository in the
 // database.
-func (g *gitHubCrawler) insertOrUpdateGhRepo(repoID int64, repo *github.Repository) bool {
+func (g *gitHubCrawler) insertOrUpdateGhRepo(repoID int64, repo *ghRepository) bool {
 	if repo == nil {
 		glog.Error("'repo' arg given is nil")
 		return false
@@ -552,10 +970,44 @@ func (g *gitHubCrawler) insertOrUpdateGhRepo(repoID int64, repo *github.Reposito
 		"created_at",
 		"updated_at",
 		"pushed_at",
+		"license",
+		"archived",
+		"disabled",
+		"is_template",
+		"visibility",
+		"parent_github_id",
+		"source_github_id",
+		"parent_id",
+		"source_id",
+	}
+
+	var license *string
+	if repo.License != nil {
+		license = repo.License.SPDXID
+	}
+
+	// parent and source are only linked once crawled
+	var parentGitHubID, sourceGitHubID *int
+	var parentID, sourceID *int
+	if repo.Parent != nil && repo.Parent.ID != nil {
+		parentGitHubID = repo.Parent.ID
+		if id := g.getGhRepoID(repo.Parent); id > 0 {
+			parentID = &id
+		} else if id < 0 {
+			return false
+		}
+	}
+	if repo.Source != nil && repo.Source.ID != nil {
+		sourceGitHubID = repo.Source.ID
+		if id := g.getGhRepoID(repo.Source); id > 0 {
+			sourceID = &id
+		} else if id < 0 {
+			return false
+		}
 	}
 
 	var query string
-	if id := g.getGhRepoID(repo); id > 0 {
+	if id := g.getGhRepoID(&repo.Repository); id > 0 {
 		query = genUpdateQuery("gh_repositories", id, ghRepoFields...)
 	} else if id == 0 {
 		query = genInsQuery("gh_repositories", ghRepoFields...)
@@ -563,7 +1015,8 @@ func (g *gitHubCrawler) insertOrUpdateGhRepo(repoID int64, repo *github.Reposito
 		return false
 	}
 
-	_, err := g.db.Exec(query,
+	var ghRepoID int64
+	err := g.db.QueryRow(query+" RETURNING id",
 		repoID,
 		repo.FullName,
 		repo.Description,
@@ -581,13 +1034,68 @@ func (g *gitHubCrawler) insertOrUpdateGhRepo(repoID int64, repo *github.Reposito
 		repo.Size,
 		formatTimestamp(repo.CreatedAt),
 		formatTimestamp(repo.UpdatedAt),
-		formatTimestamp(repo.PushedAt))
+		formatTimestamp(repo.PushedAt),
+		license,
+		repo.Archived,
+		repo.Disabled,
+		repo.IsTemplate,
+		repo.Visibility,
+		parentGitHubID,
+		sourceGitHubID,
+		parentID,
+		sourceID).Scan(&ghRepoID)
 
 	if err != nil {
 		glog.Error(err)
 		return false
 	}
 
+	if !g.linkGhRepoForks(ghRepoID, repo.ID) {
+		return false
+	}
+
+	if g.SnapshotStats {
+		fields := []string{"stargazers_count", "forks_count", "subscribers_count", "open_issues_count", "size"}
+		stats := []*int{
+			repo.StargazersCount,
+			repo.ForksCount,
+			repo.SubscribersCount,
+			repo.OpenIssuesCount,
+			repo.Size,
+		}
+		if !insertStatsSnapshot(g.db, "gh_repository_stats", "gh_repository_id", ghRepoID, fields, stats) {
+			return false
+		}
+	}
+
+	if !g.setGhRepoTopics(ghRepoID, repo.Topics) {
+		return false
+	}
+
+	if g.CrawlStargazers {
+		if !g.crawlStargazers(ghRepoID, repo) {
+			return false
+		}
+	}
+
+	if g.CrawlWatchers {
+		if !g.crawlWatchers(ghRepoID, repo) {
+			return false
+		}
+	}
+
+	if g.CrawlIssues {
+		if !g.crawlIssues(ghRepoID, repo) {
+			return false
+		}
+	}
+
+	if g.CrawlReleases {
+		if !g.crawlReleases(ghRepoID, repo) {
+			return false
+		}
+	}
+
 	if ghOrganizationID != nil {
 		if !g.insertOrUpdateGhOrg(repo.Organization.Login, repoID) {
 			return false
@@ -597,6 +1105,61 @@ func (g *gitHubCrawler) insertOrUpdateGhRepo(repoID int64, repo *github.Reposito
 	return true
 }
 
+// linkGhRepoForks links the forks crawled before the github repository
+// identified by ghRepoID to it, as their parent or their source.
+func (g *gitHubCrawler) linkGhRepoForks(ghRepoID int64, githubID *int) bool {
+	_, err := g.db.Exec(
+		`UPDATE gh_repositories
+		 SET parent_id = $1
+		 WHERE parent_github_id = $2 AND parent_id IS NULL`, ghRepoID, githubID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	_, err = g.db.Exec(
+		`UPDATE gh_repositories
+		 SET source_id = $1
+		 WHERE source_github_id = $2 AND source_id IS NULL`, ghRepoID, githubID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// setGhRepoTopics replaces the topics of a github repository by topics.
+func (g *gitHubCrawler) setGhRepoTopics(ghRepoID int64, topics []string) bool {
+	tx, err := g.db.Begin()
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if _, err := tx.Exec("DELETE FROM gh_repository_topics WHERE gh_repository_id=$1", ghRepoID); err != nil {
+		glog.Error(err)
+		tx.Rollback()
+		return false
+	}
+
+	query := genInsQuery("gh_repository_topics", "gh_repository_id", "topic")
+	for _, topic := range topics {
+		if _, err := tx.Exec(query, ghRepoID, topic); err != nil {
+			glog.Error(err)
+			tx.Rollback()
+			return false
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
 // insertOrUpdateGhOrg inserts, or updates, a github organization into
 // the database.
 func (g *gitHubCrawler) insertOrUpdateGhOrg(orgName *string, repoID int64) bool {
@@ -606,16 +1169,62 @@ func (g *gitHubCrawler) insertOrUpdateGhOrg(orgName *string, repoID int64) bool
 	}
 	glog.Infof("insert or update github organization: %s", *orgName)
 
-	tmp := g.call(false, g.fetchOrganization, *orgName)
-	var org *github.Organization
-	switch tmp.(type) {
-	case *github.Organization:
-		org = tmp.(*github.Organization)
-	default:
-		glog.Error("invalid function return type")
-		return false
+	var orgID int64
+	tmp, err := g.callErr(false, g.fetchOrganization, *orgName)
+	if err == errNotModified {
+		glog.Infof("github organization %s not modified since the last crawl", *orgName)
+		if id := g.getGhOrgIDByLogin(*orgName); id > 0 {
+			orgID = int64(id)
+		} else if id == 0 {
+			// the organization is not in the database anymore
+			g.etags.forget(ghOrgURL(*orgName))
+			return g.insertOrUpdateGhOrg(orgName, repoID)
+		} else {
+			return false
+		}
+	} else {
+		var org *github.Organization
+		switch tmp.(type) {
+		case *github.Organization:
+			org = tmp.(*github.Organization)
+		default:
+			glog.Error("invalid function return type")
+			return false
+		}
+
+		if orgID = g.saveGhOrg(org); orgID <= 0 {
+			return false
+		}
+		g.etags.commit(ghOrgURL(*orgName))
 	}
 
+	// members are always crawled since they need to be linked to the
+	// repository
+	for page := 1; page != 0; {
+		tmp = g.call(false, g.fetchOrganizationMembers, *orgName, page)
+		// the repository is still saved without the remaining members
+		members, ok := tmp.(ghMembersPage)
+		if !ok {
+			glog.Error("invalid function return type")
+			break
+		}
+
+		for _, user := range members.users {
+			if !g.insertOrUpdateUser(user.Login, repoID, orgID) {
+				return false
+			}
+		}
+
+		page = members.nextPage
+	}
+
+	return true
+}
+
+// saveGhOrg inserts, or updates, the information about a github organization
+// into the database. It returns the id of the organization in
+// gh_organizations table, or -1 if an error occurs.
+func (g *gitHubCrawler) saveGhOrg(org *github.Organization) int64 {
 	ghOrgFields := []string{
 		"login",
 		"github_id",
@@ -637,7 +1246,7 @@ func (g *gitHubCrawler) insertOrUpdateGhOrg(orgName *string, repoID int64) bool
 	} else if id == 0 {
 		query = genInsQuery("gh_organizations", ghOrgFields...)
 	} else {
-		return false
+		return -1
 	}
 
 	var orgID int64
@@ -657,25 +1266,10 @@ func (g *gitHubCrawler) insertOrUpdateGhOrg(orgName *string, repoID int64) bool
 
 	if err != nil {
 		glog.Error(err)
-		return false
-	}
-
-	tmp = g.call(false, g.fetchOrganizationMembers, *org.Login)
-	var users []github.User
-	switch tmp.(type) {
-	case []github.User:
-		users = tmp.([]github.User)
-	default:
-		glog.Error("invalid function return type")
-	}
-
-	for _, user := range users {
-		if !g.insertOrUpdateUser(user.Login, repoID, orgID) {
-			return false
-		}
+		return -1
 	}
 
-	return true
+	return orgID
 }
 
 // insertOrUpdateUser inserts, or updates, a github user into the database.
@@ -691,7 +1285,11 @@ func (g *gitHubCrawler) insertOrUpdateUser(username *string, repoID int64, orgID
 		return false
 	}
 
-	tmp := g.call(false, g.fetchUser, *username)
+	tmp, err := g.callErr(false, g.fetchUser, *username)
+	if err == errNotModified {
+		return g.linkUnmodifiedUser(*username, repoID, orgID)
+	}
+
 	var user *github.User
 	switch tmp.(type) {
 	case *github.User:
@@ -701,6 +1299,18 @@ func (g *gitHubCrawler) insertOrUpdateUser(username *string, repoID int64, orgID
 		return false
 	}
 
+	if !g.saveUser(user, repoID, orgID) {
+		return false
+	}
+	g.etags.commit(ghUserURL(*username))
+
+	return true
+}
+
+// saveUser inserts, or updates, a user and the corresponding github user into
+// the database, and links them to the given repository and github
+// organization (if any).
+func (g *gitHubCrawler) saveUser(user *github.User, repoID int64, orgID int64) bool {
 	userFields := []string{"username", "name", "email"}
 
 	var query string
@@ -719,14 +1329,40 @@ func (g *gitHubCrawler) insertOrUpdateUser(username *string, repoID int64, orgID
 		return false
 	}
 
-	if !g.linkUserToRepo(userID, repoID) {
+	if !linkUserToRepo(g.db, userID, repoID) {
 		return false
 	}
 
-	if !g.insertOrUpdateGhUser(userID, user, orgID) {
+	return g.insertOrUpdateGhUser(userID, user, orgID)
+}
+
+// linkUnmodifiedUser links a github user that has not been modified since
+// the last crawl to the given repository and github organization (if any).
+func (g *gitHubCrawler) linkUnmodifiedUser(username string, repoID int64, orgID int64) bool {
+	glog.Infof("github user %s not modified since the last crawl", username)
+
+	var userID, ghUserID int64
+	err := g.db.QueryRow("SELECT user_id, id FROM gh_users WHERE login=$1", username).Scan(&userID, &ghUserID)
+	switch {
+	case err == sql.ErrNoRows:
+		// the user is not in the database anymore
+		g.etags.forget(ghUserURL(username))
+		return g.insertOrUpdateUser(&username, repoID, orgID)
+	case err != nil:
+		glog.Error(err)
 		return false
 	}
 
+	if !linkUserToRepo(g.db, userID, repoID) {
+		return false
+	}
+
+	if orgID != 0 {
+		if !g.linkGhUserToGhOrg(ghUserID, orgID) {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -795,47 +1431,23 @@ func (g *gitHubCrawler) insertOrUpdateGhUser(userID int64, user *github.User, or
 		return false
 	}
 
-	if orgID != 0 {
-		if !g.linkGhUserToGhOrg(ghUserID, orgID) {
+	if g.SnapshotStats {
+		fields := []string{"followers_count", "following_count", "public_repos_count", "public_gists_count"}
+		stats := []*int{
+			user.Followers,
+			user.Following,
+			user.PublicRepos,
+			user.PublicGists,
+		}
+		if !insertStatsSnapshot(g.db, "gh_user_stats", "gh_user_id", ghUserID, fields, stats) {
 			return false
 		}
 	}
 
-	return true
-}
-
-// isUserLinkedToRepo checks whether a user is already linked to the given
-// repository.
-func (g *gitHubCrawler) isUserLinkedToRepo(userID, repoID int64) bool {
-	row := g.db.QueryRow(
-		`SELECT COUNT(*) AS total
-		 FROM users_repositories
-		 WHERE user_id = $1 AND repository_id = $2`, userID, repoID)
-
-	var total int64
-	if err := row.Scan(&total); err != nil {
-		glog.Error(err)
-		return false
-	}
-
-	return total > 0
-}
-
-// linkUserToRepo creates a many to many relationship between a user and a
-// repository.
-func (g *gitHubCrawler) linkUserToRepo(userID, repoID int64) bool {
-	if g.isUserLinkedToRepo(userID, repoID) {
-		return true
-	}
-
-	fields := []string{"user_id", "repository_id"}
-
-	query := genInsQuery("users_repositories", fields...)
-
-	_, err := g.db.Exec(query, userID, repoID)
-	if err != nil {
-		glog.Error(err)
-		return false
+	if orgID != 0 {
+		if !g.linkGhUserToGhOrg(ghUserID, orgID) {
+			return false
+		}
 	}
 
 	return true
@@ -895,9 +1507,12 @@ func (g *gitHubCrawler) fetchOrganization(args ...interface{}) (interface{}, err
 		return nil, errInvalidParamType
 	}
 
-	org, resp, err := g.client.Organizations.Get(orgName)
+	org := new(github.Organization)
+	resp, err := g.getConditional(ghOrgURL(orgName), org)
 	if err != nil {
-		glog.Error(err)
+		if err != errNotModified {
+			glog.Error(err)
+		}
 		return nil, g.genAPICallFuncError(resp, err)
 	}
 
@@ -922,24 +1537,28 @@ func (g *gitHubCrawler) fetchUser(args ...interface{}) (interface{}, error) {
 		return nil, errInvalidParamType
 	}
 
-	user, resp, err := g.client.Users.Get(username)
+	user := new(github.User)
+	resp, err := g.getConditional(ghUserURL(username), user)
 	if err != nil {
-		glog.Error(err)
+		if err != errNotModified {
+			glog.Error(err)
+		}
 		return nil, g.genAPICallFuncError(resp, err)
 	}
 
 	return user, nil
 }
 
-// fetchContributors fetches all the contributors of a GitHub repository.
+// fetchContributors fetches a page of contributors of a GitHub repository.
 //
-// args expects 2 values:
+// args expects 3 values:
 // - owner: the repository owner
 // - repoName:  the repository name
+// - page: the page number, starting at 1
 //
-// It returns a list of users.
+// It returns a ghContributorsPage.
 func (g *gitHubCrawler) fetchContributors(args ...interface{}) (interface{}, error) {
-	if len(args) != 2 {
+	if len(args) != 3 {
 		glog.Error("invalid number of arguments")
 		return nil, errInvalidArgs
 	}
@@ -962,23 +1581,35 @@ func (g *gitHubCrawler) fetchContributors(args ...interface{}) (interface{}, err
 		return nil, errInvalidParamType
 	}
 
-	users, resp, err := g.client.Repositories.ListContributors(owner, repoName, nil)
+	var page int
+	switch args[2].(type) {
+	case int:
+		page = args[2].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[2]))
+		return nil, errInvalidParamType
+	}
+
+	opt := &github.ListContributorsOptions{ListOptions: github.ListOptions{PerPage: 100, Page: page}}
+	users, resp, err := g.client.Repositories.ListContributors(owner, repoName, opt)
 	if err != nil {
 		glog.Error(err)
 		return nil, g.genAPICallFuncError(resp, err)
 	}
 
-	return users, nil
+	return ghContributorsPage{contributors: users, nextPage: resp.NextPage}, nil
 }
 
-// fetchOrganizationMembers fetches all the members of a GitHub organization.
+// fetchOrganizationMembers fetches a page of members of a GitHub
+// organization.
 //
-// args expects 1 values:
+// args expects 2 values:
 // - orgName: the organization name
+// - page: the page number, starting at 1
 //
-// It returns a list of users.
+// It returns a ghMembersPage.
 func (g *gitHubCrawler) fetchOrganizationMembers(args ...interface{}) (interface{}, error) {
-	if len(args) != 1 {
+	if len(args) != 2 {
 		glog.Error("invalid number of arguments")
 		return nil, errInvalidArgs
 	}
@@ -992,13 +1623,69 @@ func (g *gitHubCrawler) fetchOrganizationMembers(args ...interface{}) (interface
 		return nil, errInvalidParamType
 	}
 
-	users, resp, err := g.client.Organizations.ListMembers(orgName, nil)
+	var page int
+	switch args[1].(type) {
+	case int:
+		page = args[1].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[1]))
+		return nil, errInvalidParamType
+	}
+
+	opt := &github.ListMembersOptions{ListOptions: github.ListOptions{PerPage: 100, Page: page}}
+	users, resp, err := g.client.Organizations.ListMembers(orgName, opt)
 	if err != nil {
 		glog.Error(err)
 		return nil, g.genAPICallFuncError(resp, err)
 	}
 
-	return users, nil
+	return ghMembersPage{users: users, nextPage: resp.NextPage}, nil
+}
+
+// getConditional fetches the GitHub API resource located at urlStr into v.
+// When ETags are in use, the request is conditional and errNotModified is
+// returned if the resource has not been modified since it was last fetched.
+// The validators of the fetched resource must be committed once the resource
+// is saved into the database.
+func (g *gitHubCrawler) getConditional(urlStr string, v interface{}) (*github.Response, error) {
+	req, err := g.client.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+	g.etags.setConditionalHeaders(urlStr, req)
+
+	resp, err := g.client.Do(req, v)
+	if resp != nil && resp.StatusCode == http.StatusNotModified {
+		return resp, errNotModified
+	}
+	if err != nil {
+		return resp, err
+	}
+	g.etags.set(urlStr, resp.Header)
+
+	return resp, nil
+}
+
+// ghClonePath returns the path of the clone of a repository, relative to the
+// clone directory.
+func ghClonePath(repo *github.Repository) string {
+	return strings.ToLower(filepath.Join(*repo.Language, *repo.Owner.Login, *repo.Name))
+}
+
+// ghRepoURL returns the URL of a repository, relative to the GitHub API URL.
+func ghRepoURL(owner, repo string) string {
+	return fmt.Sprintf("repos/%v/%v", owner, repo)
+}
+
+// ghOrgURL returns the URL of an organization, relative to the GitHub API
+// URL.
+func ghOrgURL(orgName string) string {
+	return fmt.Sprintf("orgs/%v", orgName)
+}
+
+// ghUserURL returns the URL of a user, relative to the GitHub API URL.
+func ghUserURL(username string) string {
+	return fmt.Sprintf("users/%v", username)
 }
 
 // genAPICallFuncError creates an error base on the http response.
@@ -1011,7 +1698,11 @@ func (g *gitHubCrawler) genAPICallFuncError(resp *github.Response, err error) er
 		return errNilArg
 	}
 
-	if err == nil || resp.StatusCode != 403 {
+	if err == nil {
+		return nil
+	}
+
+	if resp.StatusCode != http.StatusForbidden {
 		return err
 	}
 
@@ -0,0 +1,100 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/google/go-github/github"
+)
+
+// crawlForks crawls the forks of a repository and, recursively, their forks
+// down to the given depth. Forks are crawled regardless of the fork option of
+// the crawler and do not count against its limit.
+func (g *gitHubCrawler) crawlForks(repo *ghRepository, depth int) {
+	if depth <= 0 || (repo.ForksCount != nil && *repo.ForksCount == 0) {
+		return
+	}
+	glog.Infof("crawl forks of repository: %s", *repo.Name)
+
+	// the source of a fork network is its root repository, which is unknown
+	// when repo is a fork whose source is not known
+	source := repo.Source
+	if source == nil && repo.Fork != nil && !*repo.Fork {
+		source = &github.Repository{ID: repo.ID}
+	}
+
+	for page := 1; page != 0; {
+		tmp := g.call(false, g.fetchForks, *repo.Owner.Login, *repo.Name, page)
+		var forks ghRepositoriesPage
+		switch tmp.(type) {
+		case ghRepositoriesPage:
+			forks = tmp.(ghRepositoriesPage)
+		default:
+			glog.Error("invalid function return type")
+			return
+		}
+
+		for i := range forks.repos {
+			fork := &forks.repos[i]
+
+			// listed forks do not tell their parent and their source
+			fork.Parent = &github.Repository{ID: repo.ID}
+			fork.Source = source
+
+			// forks usually have the language of their parent
+			if fork.Language == nil {
+				fork.Language = repo.Language
+			}
+
+			if err := verifyRepo(&fork.Repository); err != nil {
+				glog.Error(err)
+				continue
+			}
+
+			if !g.insertOrUpdateRepo(fork, nil) {
+				continue
+			}
+
+			g.crawlForks(fork, depth-1)
+		}
+
+		page = forks.nextPage
+	}
+}
+
+// fetchForks fetches a page of the forks of a repository, oldest first.
+//
+// args expects 3 values:
+// - owner: the repository owner
+// - repo: the repository name
+// - page: the page number, starting at 1
+//
+// It returns a ghRepositoriesPage.
+func (g *gitHubCrawler) fetchForks(args ...interface{}) (interface{}, error) {
+	owner, repo, page, err := repoPageArgs(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// forks are not fetched with g.client.Repositories since github.Repository
+	// lacks some of the fields of the forks
+	urlStr := fmt.Sprintf("%v/forks?sort=oldest&per_page=100&page=%d", ghRepoURL(owner, repo), page)
+	req, err := g.client.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	var forks []ghRepository
+	resp, err := g.client.Do(req, &forks)
+	if err != nil {
+		glog.Error(err)
+		return nil, g.genAPICallFuncError(resp, err)
+	}
+
+	return ghRepositoriesPage{repos: forks, nextPage: resp.NextPage}, nil
+}
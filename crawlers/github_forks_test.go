@@ -0,0 +1,36 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestFetchForks(t *testing.T) {
+	g, closeFn := newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		expectRequest(t, r, "GET", "/repos/DevMine/crawld/forks")
+		if page := r.URL.Query().Get("page"); page != "1" {
+			t.Errorf("page: expected '1', found '%s'", page)
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s/repos/DevMine/crawld/forks?page=2>; rel="next"`, r.Host))
+		fmt.Fprint(w, `[{"id": 2, "name": "crawld", "fork": true, "owner": {"login": "gopher"}}]`)
+	})
+	defer closeFn()
+
+	tmp, err := g.fetchForks("DevMine", "crawld", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forks := tmp.(ghRepositoriesPage)
+	if len(forks.repos) != 1 || *forks.repos[0].Owner.Login != "gopher" {
+		t.Errorf("fetchForks: expected the fork of gopher, found %+v", forks.repos)
+	}
+	if forks.nextPage != 2 {
+		t.Errorf("fetchForks: expected next page 2, found %d", forks.nextPage)
+	}
+}
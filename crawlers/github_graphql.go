@@ -0,0 +1,746 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/go-github/github"
+)
+
+// graphQLBatchSize is the number of repositories fetched by a single GraphQL
+// query.
+const graphQLBatchSize = 20
+
+// GraphQL fragments of the fields fetched for users, organization members and
+// repositories. A query must only contain the fragments it uses.
+const (
+	gqlUserFragment = `
+fragment userFields on User {
+  databaseId login name email bio company location avatarUrl url websiteUrl
+  isHireable createdAt updatedAt
+  followers { totalCount }
+  following { totalCount }
+  repositories(privacy: PUBLIC) { totalCount }
+  gists(privacy: PUBLIC) { totalCount }
+}`
+
+	gqlMembersFragment = `
+fragment membersFields on OrganizationMemberConnection {
+  pageInfo { hasNextPage endCursor }
+  nodes { ...userFields }
+}`
+
+	gqlRepositoryFragment = `
+fragment repositoryFields on Repository {
+  databaseId name nameWithOwner description homepageUrl isFork url diskUsage
+  forkCount stargazerCount createdAt updatedAt pushedAt
+  isArchived isDisabled isTemplate visibility
+  defaultBranchRef { name }
+  licenseInfo { spdxId }
+  parent { databaseId }
+  repositoryTopics(first: 100) { nodes { topic { name } } }
+  primaryLanguage { name }
+  watchers { totalCount }
+  issues(states: OPEN) { totalCount }
+  pullRequests(states: OPEN) { totalCount }
+  languages(first: 100) { edges { size node { name } } }
+  owner {
+    __typename
+    login
+    ... on User { ...userFields }
+    ... on Organization {
+      databaseId name email location avatarUrl url websiteUrl createdAt updatedAt
+      membersWithRole(first: 100) { ...membersFields }
+    }
+  }
+}`
+)
+
+// gqlRequest is the body of a request to the GitHub GraphQL API.
+type gqlRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// gqlResponse is the body of a response of the GitHub GraphQL API.
+type gqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []gqlError      `json:"errors"`
+}
+
+// gqlError is an error reported by the GitHub GraphQL API.
+type gqlError struct {
+	Type    string        `json:"type"`
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path"`
+}
+
+// gqlRateLimit is the rate limit status of the GitHub GraphQL API, in points.
+type gqlRateLimit struct {
+	Cost      int       `json:"cost"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// gqlCount is the total count of a GraphQL connection.
+type gqlCount struct {
+	TotalCount int `json:"totalCount"`
+}
+
+// gqlName is a GraphQL object only queried for its name.
+type gqlName struct {
+	Name string `json:"name"`
+}
+
+// gqlUser is a GitHub user, as fetched with the GraphQL API.
+type gqlUser struct {
+	DatabaseID *int       `json:"databaseId"`
+	Login      *string    `json:"login"`
+	Name       *string    `json:"name"`
+	Email      *string    `json:"email"`
+	Bio        *string    `json:"bio"`
+	Company    *string    `json:"company"`
+	Location   *string    `json:"location"`
+	AvatarURL  *string    `json:"avatarUrl"`
+	URL        *string    `json:"url"`
+	WebsiteURL *string    `json:"websiteUrl"`
+	IsHireable *bool      `json:"isHireable"`
+	CreatedAt  *time.Time `json:"createdAt"`
+	UpdatedAt  *time.Time `json:"updatedAt"`
+	Followers  *gqlCount  `json:"followers"`
+	Following  *gqlCount  `json:"following"`
+
+	// Repositories and Gists only count the public ones.
+	Repositories *gqlCount `json:"repositories"`
+	Gists        *gqlCount `json:"gists"`
+}
+
+// gqlMembers is a page of members of a GitHub organization, as fetched with
+// the GraphQL API.
+type gqlMembers struct {
+	PageInfo struct {
+		HasNextPage bool    `json:"hasNextPage"`
+		EndCursor   *string `json:"endCursor"`
+	} `json:"pageInfo"`
+	Nodes []gqlUser `json:"nodes"`
+}
+
+// gqlOwner is the owner of a GitHub repository, either a user or an
+// organization, as fetched with the GraphQL API.
+type gqlOwner struct {
+	gqlUser
+
+	Typename        string      `json:"__typename"`
+	MembersWithRole *gqlMembers `json:"membersWithRole"`
+}
+
+// gqlRepository is a GitHub repository, as fetched with the GraphQL API.
+type gqlRepository struct {
+	DatabaseID       *int       `json:"databaseId"`
+	Name             *string    `json:"name"`
+	NameWithOwner    *string    `json:"nameWithOwner"`
+	Description      *string    `json:"description"`
+	HomepageURL      *string    `json:"homepageUrl"`
+	IsFork           *bool      `json:"isFork"`
+	URL              *string    `json:"url"`
+	DiskUsage        *int       `json:"diskUsage"`
+	ForkCount        *int       `json:"forkCount"`
+	StargazerCount   *int       `json:"stargazerCount"`
+	CreatedAt        *time.Time `json:"createdAt"`
+	UpdatedAt        *time.Time `json:"updatedAt"`
+	PushedAt         *time.Time `json:"pushedAt"`
+	IsArchived       *bool      `json:"isArchived"`
+	IsDisabled       *bool      `json:"isDisabled"`
+	IsTemplate       *bool      `json:"isTemplate"`
+	Visibility       *string    `json:"visibility"`
+	DefaultBranchRef *gqlName   `json:"defaultBranchRef"`
+	PrimaryLanguage  *gqlName   `json:"primaryLanguage"`
+	Watchers         gqlCount   `json:"watchers"`
+	Issues           gqlCount   `json:"issues"`
+	PullRequests     gqlCount   `json:"pullRequests"`
+	Languages        struct {
+		Edges []struct {
+			Size int     `json:"size"`
+			Node gqlName `json:"node"`
+		} `json:"edges"`
+	} `json:"languages"`
+	LicenseInfo *struct {
+		SPDXID *string `json:"spdxId"`
+	} `json:"licenseInfo"`
+	Parent *struct {
+		DatabaseID *int `json:"databaseId"`
+	} `json:"parent"`
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic gqlName `json:"topic"`
+		} `json:"nodes"`
+	} `json:"repositoryTopics"`
+	Owner gqlOwner `json:"owner"`
+}
+
+// crawlGraphQLBatch crawls a batch of repositories, given by their owner and
+// name, using the GraphQL API. Each repository is fetched along with its
+// owner, its languages and, for organizations, their members in a single
+// query. n is the number of repositories left to crawl and is decremented for
+// each repository saved into the database.
+func (g *gitHubCrawler) crawlGraphQLBatch(batch []github.Repository, n *int64) {
+	if len(batch) == 0 {
+		return
+	}
+
+	tmp := g.callGraphQL(g.fetchGraphQLRepositories, batch)
+	var repos []*gqlRepository
+	switch tmp.(type) {
+	case []*gqlRepository:
+		repos = tmp.([]*gqlRepository)
+	default:
+		glog.Error("invalid function return type")
+		return
+	}
+
+	for _, r := range repos {
+		// the repository has been deleted since it was listed
+		if r == nil {
+			continue
+		}
+
+		if ok, err := isLanguageWanted(g.Languages, r.languages()); err != nil {
+			glog.Error(err)
+			continue
+		} else if !ok {
+			continue
+		}
+
+		// skip when an the method fail because the repository is not
+		// saved into the DB
+		if !g.insertOrUpdateGraphQLRepo(r) {
+			continue
+		}
+
+		// forks are crawled with the REST API
+		g.crawlForks(r.repository(), g.ForkDepth)
+
+		*n--
+	}
+}
+
+// insertOrUpdateGraphQLRepo inserts or updates a repository fetched with the
+// GraphQL API, along with its languages, its owner and, for organizations,
+// their members.
+// It fills the same tables as insertOrUpdateRepo.
+func (g *gitHubCrawler) insertOrUpdateGraphQLRepo(r *gqlRepository) bool {
+	repo := r.repository()
+	if err := verifyRepo(&repo.Repository); err != nil {
+		glog.Error(err)
+		return false
+	}
+	glog.Infof("insert or update repository: %s", *repo.Name)
+
+	repoID := g.saveRepo(&repo.Repository)
+	if repoID <= 0 {
+		return false
+	}
+
+	if !setRepoLanguages(g.db, repoID, r.languages()) {
+		return false
+	}
+
+	if r.Owner.Typename != "Organization" {
+		if !g.saveGraphQLUser(&r.Owner.gqlUser, repoID, 0) {
+			return false
+		}
+	} else {
+		if !g.insertOrUpdateGraphQLOrg(&r.Owner, repoID) {
+			return false
+		}
+	}
+
+	if !g.insertOrUpdateGhRepo(repoID, repo) {
+		return false
+	}
+
+	if g.CrawlContributors {
+		if !g.insertOrUpdateGraphQLContributors(*repo.Owner.Login, *repo.Name, repoID) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// insertOrUpdateGraphQLContributors inserts, or updates, all the contributors
+// of a repository into the database, along with their number of
+// contributions to the repository. The GraphQL API does not tell the number
+// of contributions so contributors are listed with the REST API, but their
+// profiles are fetched with a single GraphQL query per page of contributors.
+func (g *gitHubCrawler) insertOrUpdateGraphQLContributors(owner, repoName string, repoID int64) bool {
+	for page := 1; page != 0; {
+		tmp := g.call(false, g.fetchContributors, owner, repoName, page)
+		var contributors ghContributorsPage
+		switch tmp.(type) {
+		case ghContributorsPage:
+			contributors = tmp.(ghContributorsPage)
+		default:
+			glog.Error("invalid function return type")
+			return false
+		}
+
+		var logins []string
+		contributions := map[int]int{}
+		for _, c := range contributors.contributors {
+			if c.Login == nil || c.ID == nil || c.Contributions == nil {
+				glog.Error("'contributor' has nil Login, ID or Contributions field")
+				continue
+			}
+			logins = append(logins, *c.Login)
+			contributions[*c.ID] = *c.Contributions
+		}
+
+		if len(logins) > 0 {
+			tmp = g.callGraphQL(g.fetchGraphQLUsers, logins)
+			var users []*gqlUser
+			switch tmp.(type) {
+			case []*gqlUser:
+				users = tmp.([]*gqlUser)
+			default:
+				glog.Error("invalid function return type")
+				return false
+			}
+
+			for _, u := range users {
+				// bots and deleted accounts are not GitHub users
+				if u == nil || u.DatabaseID == nil {
+					continue
+				}
+
+				if !g.saveGraphQLUser(u, repoID, 0) {
+					return false
+				}
+
+				userID := g.getUserID(&github.User{ID: u.DatabaseID})
+				if userID <= 0 {
+					return false
+				}
+
+				if !setUserRepoContributions(g.db, int64(userID), repoID, contributions[*u.DatabaseID]) {
+					return false
+				}
+			}
+		}
+
+		page = contributors.nextPage
+	}
+
+	return true
+}
+
+// insertOrUpdateGraphQLOrg inserts, or updates, a github organization fetched
+// with the GraphQL API into the database, along with all its members.
+func (g *gitHubCrawler) insertOrUpdateGraphQLOrg(owner *gqlOwner, repoID int64) bool {
+	if owner.DatabaseID == nil || owner.Login == nil || owner.CreatedAt == nil || owner.UpdatedAt == nil {
+		glog.Error("'owner' has nil DatabaseID, Login, CreatedAt or UpdatedAt field")
+		return false
+	}
+	glog.Infof("insert or update github organization: %s", *owner.Login)
+
+	orgID := g.saveGhOrg(owner.organization())
+	if orgID <= 0 {
+		return false
+	}
+
+	members := owner.MembersWithRole
+	for members != nil {
+		for i := range members.Nodes {
+			if !g.saveGraphQLUser(&members.Nodes[i], repoID, orgID) {
+				return false
+			}
+		}
+
+		if !members.PageInfo.HasNextPage || members.PageInfo.EndCursor == nil {
+			break
+		}
+
+		tmp := g.callGraphQL(g.fetchGraphQLOrganizationMembers, *owner.Login, *members.PageInfo.EndCursor)
+		switch tmp.(type) {
+		case *gqlMembers:
+			members = tmp.(*gqlMembers)
+		default:
+			glog.Error("invalid function return type")
+			return false
+		}
+	}
+
+	return true
+}
+
+// saveGraphQLUser inserts, or updates, a github user fetched with the GraphQL
+// API into the database.
+func (g *gitHubCrawler) saveGraphQLUser(user *gqlUser, repoID int64, orgID int64) bool {
+	if user.DatabaseID == nil || user.Login == nil {
+		glog.Error("'user' has nil DatabaseID or Login field")
+		return false
+	}
+	glog.Infof("insert or update user: %s", *user.Login)
+
+	return g.saveUser(user.user(), repoID, orgID)
+}
+
+// fetchGraphQLRepositories fetches a batch of repositories with a single
+// GraphQL query.
+//
+// args expects 1 value:
+// - repos: []github.Repository, the repositories to fetch, identified by
+// the login of their owner and their name
+//
+// It returns a list of *gqlRepository, in the same order as repos. Entries of
+// repositories that do not exist anymore are nil.
+func (g *gitHubCrawler) fetchGraphQLRepositories(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var repos []github.Repository
+	switch args[0].(type) {
+	case []github.Repository:
+		repos = args[0].([]github.Repository)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected []github.Repository)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	var params, fields []string
+	vars := map[string]interface{}{}
+	for i, repo := range repos {
+		var owner, name string
+		if repo.Owner != nil && repo.Owner.Login != nil {
+			owner = *repo.Owner.Login
+		}
+		if repo.Name != nil {
+			name = *repo.Name
+		}
+
+		params = append(params, fmt.Sprintf("$o%d: String!, $n%d: String!", i, i))
+		fields = append(fields, fmt.Sprintf("  r%d: repository(owner: $o%d, name: $n%d) { ...repositoryFields }", i, i, i))
+		vars[fmt.Sprintf("o%d", i)] = owner
+		vars[fmt.Sprintf("n%d", i)] = name
+	}
+	query := graphQLQuery(params, fields, gqlRepositoryFragment, gqlMembersFragment, gqlUserFragment)
+
+	var data map[string]json.RawMessage
+	if err := g.queryGraphQL(query, vars, &data); err != nil {
+		return nil, err
+	}
+
+	results := make([]*gqlRepository, len(repos))
+	for i := range repos {
+		if raw, ok := data[fmt.Sprintf("r%d", i)]; ok {
+			if err := json.Unmarshal(raw, &results[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// fetchGraphQLUsers fetches a batch of users with a single GraphQL query.
+//
+// args expects 1 value:
+// - logins: []string, the logins of the users to fetch
+//
+// It returns a list of *gqlUser, in the same order as logins. Entries of
+// users that do not exist are nil.
+func (g *gitHubCrawler) fetchGraphQLUsers(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var logins []string
+	switch args[0].(type) {
+	case []string:
+		logins = args[0].([]string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected []string)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	var params, fields []string
+	vars := map[string]interface{}{}
+	for i, login := range logins {
+		params = append(params, fmt.Sprintf("$l%d: String!", i))
+		fields = append(fields, fmt.Sprintf("  u%d: user(login: $l%d) { ...userFields }", i, i))
+		vars[fmt.Sprintf("l%d", i)] = login
+	}
+	query := graphQLQuery(params, fields, gqlUserFragment)
+
+	var data map[string]json.RawMessage
+	if err := g.queryGraphQL(query, vars, &data); err != nil {
+		return nil, err
+	}
+
+	users := make([]*gqlUser, len(logins))
+	for i := range logins {
+		if raw, ok := data[fmt.Sprintf("u%d", i)]; ok {
+			if err := json.Unmarshal(raw, &users[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return users, nil
+}
+
+// fetchGraphQLOrganizationMembers fetches a page of members of an
+// organization with the GraphQL API.
+//
+// args expects 2 values:
+// - login: string, the login of the organization
+// - after: string, the cursor after which members are fetched
+//
+// It returns a *gqlMembers.
+func (g *gitHubCrawler) fetchGraphQLOrganizationMembers(args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var login string
+	switch args[0].(type) {
+	case string:
+		login = args[0].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	var after string
+	switch args[1].(type) {
+	case string:
+		after = args[1].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[1]))
+		return nil, errInvalidParamType
+	}
+
+	query := graphQLQuery([]string{"$login: String!", "$after: String"},
+		[]string{"  organization(login: $login) { membersWithRole(first: 100, after: $after) { ...membersFields } }"},
+		gqlMembersFragment, gqlUserFragment)
+
+	var data struct {
+		Organization *struct {
+			MembersWithRole *gqlMembers `json:"membersWithRole"`
+		} `json:"organization"`
+	}
+	vars := map[string]interface{}{"login": login, "after": after}
+	if err := g.queryGraphQL(query, vars, &data); err != nil {
+		return nil, err
+	}
+
+	if data.Organization == nil || data.Organization.MembersWithRole == nil {
+		return nil, errors.New("fetchGraphQLOrganizationMembers: organization " + login + " not found")
+	}
+
+	return data.Organization.MembersWithRole, nil
+}
+
+// queryGraphQL sends a query to the GitHub GraphQL API and decodes the data
+// of the response into v. The query is expected to fetch the rateLimit
+// object, whose cost is recorded as the expected cost of the next queries.
+// errTooManyCall is returned if the rate limit is exceeded.
+func (g *gitHubCrawler) queryGraphQL(query string, vars map[string]interface{}, v interface{}) error {
+	req, err := g.client.NewRequest("POST", "graphql", gqlRequest{Query: query, Variables: vars})
+	if err != nil {
+		return err
+	}
+
+	var body gqlResponse
+	resp, err := g.client.Do(req, &body)
+	if err = g.genAPICallFuncError(resp, err); err != nil {
+		return err
+	}
+
+	for _, e := range body.Errors {
+		switch e.Type {
+		case "RATE_LIMITED":
+			return errTooManyCall
+		case "NOT_FOUND":
+			// the corresponding data is null
+		default:
+			glog.Errorf("graphql: %s (path: %v)", e.Message, e.Path)
+		}
+	}
+
+	if len(body.Data) == 0 || string(body.Data) == "null" {
+		return errors.New("graphql: query returned no data")
+	}
+
+	var rl struct {
+		RateLimit *gqlRateLimit `json:"rateLimit"`
+	}
+	if err := json.Unmarshal(body.Data, &rl); err != nil {
+		return err
+	}
+	if rl.RateLimit != nil {
+		g.tokens.setCost(graphqlResource, rl.RateLimit.Cost)
+	}
+
+	return json.Unmarshal(body.Data, v)
+}
+
+// graphQLQuery builds a GraphQL query with the given variable declarations,
+// fields and fragments. The rate limit status is always queried along with
+// the fields.
+func graphQLQuery(params, fields []string, fragments ...string) string {
+	return fmt.Sprintf("query(%s) {\n  rateLimit { cost remaining resetAt }\n%s\n}\n%s",
+		strings.Join(params, ", "), strings.Join(fields, "\n"), strings.Join(fragments, "\n"))
+}
+
+// languages returns the languages of the repository, with their size in
+// bytes.
+func (r *gqlRepository) languages() map[string]int {
+	langs := map[string]int{}
+	for _, e := range r.Languages.Edges {
+		langs[e.Node.Name] = e.Size
+	}
+	return langs
+}
+
+// repository converts the repository to its REST API representation. The
+// GraphQL API does not tell the source of forks.
+func (r *gqlRepository) repository() *ghRepository {
+	openIssues := r.Issues.TotalCount + r.PullRequests.TotalCount
+	subscribers := r.Watchers.TotalCount
+	ownerType := r.Owner.Typename
+
+	repo := &ghRepository{Repository: github.Repository{
+		ID:              r.DatabaseID,
+		Owner:           &github.User{Login: r.Owner.Login, Type: &ownerType},
+		Name:            r.Name,
+		FullName:        r.NameWithOwner,
+		Description:     r.Description,
+		Homepage:        r.HomepageURL,
+		Fork:            r.IsFork,
+		HTMLURL:         r.URL,
+		ForksCount:      r.ForkCount,
+		OpenIssuesCount: &openIssues,
+		StargazersCount: r.StargazerCount,
+		// the REST API names stargazers watchers and watchers subscribers
+		WatchersCount:    r.StargazerCount,
+		SubscribersCount: &subscribers,
+		Size:             r.DiskUsage,
+		CreatedAt:        toTimestamp(r.CreatedAt),
+		UpdatedAt:        toTimestamp(r.UpdatedAt),
+		PushedAt:         toTimestamp(r.PushedAt),
+	}}
+	repo.Archived = r.IsArchived
+	repo.Disabled = r.IsDisabled
+	repo.IsTemplate = r.IsTemplate
+
+	if r.Visibility != nil {
+		// the REST API spells visibilities in lowercase
+		visibility := strings.ToLower(*r.Visibility)
+		repo.Visibility = &visibility
+	}
+	if r.LicenseInfo != nil {
+		repo.License = &ghLicense{SPDXID: r.LicenseInfo.SPDXID}
+	}
+	if r.Parent != nil {
+		repo.Parent = &github.Repository{ID: r.Parent.DatabaseID}
+	}
+	for _, n := range r.RepositoryTopics.Nodes {
+		repo.Topics = append(repo.Topics, n.Topic.Name)
+	}
+
+	if r.URL != nil {
+		cloneURL := *r.URL + ".git"
+		repo.CloneURL = &cloneURL
+	}
+	if r.DefaultBranchRef != nil {
+		repo.DefaultBranch = &r.DefaultBranchRef.Name
+	}
+	if r.PrimaryLanguage != nil {
+		repo.Language = &r.PrimaryLanguage.Name
+	}
+
+	return repo
+}
+
+// user converts the user to its REST API representation.
+func (u *gqlUser) user() *github.User {
+	userType := "User"
+	user := &github.User{
+		ID:        u.DatabaseID,
+		Login:     u.Login,
+		Name:      u.Name,
+		Bio:       u.Bio,
+		Company:   u.Company,
+		Location:  u.Location,
+		AvatarURL: u.AvatarURL,
+		HTMLURL:   u.URL,
+		Blog:      u.WebsiteURL,
+		Hireable:  u.IsHireable,
+		CreatedAt: toTimestamp(u.CreatedAt),
+		UpdatedAt: toTimestamp(u.UpdatedAt),
+		Type:      &userType,
+	}
+
+	// the GraphQL API returns an empty email when it is not public
+	if u.Email != nil && len(*u.Email) != 0 {
+		user.Email = u.Email
+	}
+	if u.Followers != nil {
+		user.Followers = &u.Followers.TotalCount
+	}
+	if u.Following != nil {
+		user.Following = &u.Following.TotalCount
+	}
+	if u.Repositories != nil {
+		user.PublicRepos = &u.Repositories.TotalCount
+	}
+	if u.Gists != nil {
+		user.PublicGists = &u.Gists.TotalCount
+	}
+
+	return user
+}
+
+// organization converts the owner to the REST API representation of an
+// organization.
+func (o *gqlOwner) organization() *github.Organization {
+	org := &github.Organization{
+		ID:        o.DatabaseID,
+		Login:     o.Login,
+		Name:      o.Name,
+		Location:  o.Location,
+		AvatarURL: o.AvatarURL,
+		HTMLURL:   o.URL,
+		Blog:      o.WebsiteURL,
+		CreatedAt: o.CreatedAt,
+		UpdatedAt: o.UpdatedAt,
+	}
+
+	if o.Email != nil && len(*o.Email) != 0 {
+		org.Email = o.Email
+	}
+
+	return org
+}
+
+// toTimestamp converts a time to a github.Timestamp.
+func toTimestamp(t *time.Time) *github.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return &github.Timestamp{Time: *t}
+}
@@ -0,0 +1,134 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+// newGraphQLTestCrawler creates a GitHub crawler whose GraphQL queries are
+// served by handler.
+func newGraphQLTestCrawler(t *testing.T, handler http.HandlerFunc) (*gitHubCrawler, func()) {
+	return newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		expectRequest(t, r, "POST", "/graphql")
+		handler(w, r)
+	})
+}
+
+func TestFetchGraphQLRepositories(t *testing.T) {
+	g, closeFn := newGraphQLTestCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		var req gqlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		if req.Variables["o0"] != "DevMine" || req.Variables["n0"] != "crawld" {
+			t.Errorf("unexpected variables: %v", req.Variables)
+		}
+
+		fmt.Fprint(w, `{
+			"data": {
+				"rateLimit": {"cost": 3, "remaining": 4997, "resetAt": "2015-06-01T12:00:00Z"},
+				"r0": {
+					"databaseId": 42, "name": "crawld", "nameWithOwner": "DevMine/crawld",
+					"isFork": false, "url": "https://github.com/DevMine/crawld",
+					"isArchived": true, "visibility": "PUBLIC",
+					"licenseInfo": {"spdxId": "BSD-3-Clause"},
+					"repositoryTopics": {"nodes": [{"topic": {"name": "crawler"}}]},
+					"stargazerCount": 10, "watchers": {"totalCount": 3},
+					"issues": {"totalCount": 2}, "pullRequests": {"totalCount": 1},
+					"defaultBranchRef": {"name": "master"},
+					"primaryLanguage": {"name": "Go"},
+					"languages": {"edges": [{"size": 1234, "node": {"name": "Go"}}]},
+					"owner": {
+						"__typename": "Organization", "login": "DevMine", "databaseId": 7,
+						"membersWithRole": {
+							"pageInfo": {"hasNextPage": false, "endCursor": null},
+							"nodes": [{"databaseId": 1, "login": "gopher", "email": "",
+								"repositories": {"totalCount": 12}, "gists": {"totalCount": 4}}]
+						}
+					}
+				},
+				"r1": null
+			},
+			"errors": [{"type": "NOT_FOUND", "message": "not found", "path": ["r1"]}]
+		}`)
+	})
+	defer closeFn()
+
+	batch := []github.Repository{
+		{Owner: &github.User{Login: github.String("DevMine")}, Name: github.String("crawld")},
+		{Owner: &github.User{Login: github.String("DevMine")}, Name: github.String("deleted")},
+	}
+	tmp, err := g.fetchGraphQLRepositories(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repos, ok := tmp.([]*gqlRepository)
+	if !ok || len(repos) != 2 {
+		t.Fatalf("fetchGraphQLRepositories: expected 2 repositories, found %v", tmp)
+	}
+	if repos[1] != nil {
+		t.Errorf("fetchGraphQLRepositories: expected nil deleted repository, found %v", repos[1])
+	}
+
+	repo := repos[0].repository()
+	if err := verifyRepo(&repo.Repository); err != nil {
+		t.Fatal(err)
+	}
+	if *repo.CloneURL != "https://github.com/DevMine/crawld.git" {
+		t.Errorf("CloneURL: expected 'https://github.com/DevMine/crawld.git', found %q", *repo.CloneURL)
+	}
+	if *repo.Owner.Type != "Organization" {
+		t.Errorf("Owner.Type: expected 'Organization', found %q", *repo.Owner.Type)
+	}
+	if *repo.OpenIssuesCount != 3 || *repo.SubscribersCount != 3 || *repo.WatchersCount != 10 {
+		t.Errorf("counts: expected 3 open issues, 3 subscribers and 10 watchers, found %d, %d and %d",
+			*repo.OpenIssuesCount, *repo.SubscribersCount, *repo.WatchersCount)
+	}
+	if !*repo.Archived || *repo.Visibility != "public" || *repo.License.SPDXID != "BSD-3-Clause" {
+		t.Errorf("flags: expected archived public BSD-3-Clause repository, found %v, %q and %q",
+			*repo.Archived, *repo.Visibility, *repo.License.SPDXID)
+	}
+	if len(repo.Topics) != 1 || repo.Topics[0] != "crawler" {
+		t.Errorf("Topics: expected [crawler], found %v", repo.Topics)
+	}
+	if langs := repos[0].languages(); langs["Go"] != 1234 {
+		t.Errorf("languages: expected Go: 1234, found %v", langs)
+	}
+
+	members := repos[0].Owner.MembersWithRole
+	if members == nil || len(members.Nodes) != 1 {
+		t.Fatalf("members: expected 1 member, found %v", members)
+	}
+	user := members.Nodes[0].user()
+	if user.Email != nil {
+		t.Errorf("user email: expected nil, found %q", *user.Email)
+	}
+	if user.PublicRepos == nil || *user.PublicRepos != 12 || user.PublicGists == nil || *user.PublicGists != 4 {
+		t.Errorf("user counts: expected 12 public repos and 4 public gists, found %v and %v",
+			user.PublicRepos, user.PublicGists)
+	}
+
+	if cost := g.tokens.costs[graphqlResource]; cost != 3 {
+		t.Errorf("graphql cost: expected 3, found %d", cost)
+	}
+}
+
+func TestQueryGraphQLRateLimited(t *testing.T) {
+	g, closeFn := newGraphQLTestCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": null, "errors": [{"type": "RATE_LIMITED", "message": "API rate limit exceeded"}]}`)
+	})
+	defer closeFn()
+
+	if _, err := g.fetchGraphQLUsers([]string{"gopher"}); err != errTooManyCall {
+		t.Errorf("fetchGraphQLUsers: expected errTooManyCall, found %v", err)
+	}
+}
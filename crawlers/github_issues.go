@@ -0,0 +1,256 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/google/go-github/github"
+)
+
+// ghIssue is an issue, or a pull request, of a GitHub repository as listed
+// by the issues API.
+type ghIssue struct {
+	ID        *int              `json:"id,omitempty"`
+	Number    *int              `json:"number,omitempty"`
+	State     *string           `json:"state,omitempty"`
+	Title     *string           `json:"title,omitempty"`
+	User      *github.User      `json:"user,omitempty"`
+	Labels    []ghLabel         `json:"labels,omitempty"`
+	Comments  *int              `json:"comments,omitempty"`
+	CreatedAt *github.Timestamp `json:"created_at,omitempty"`
+	UpdatedAt *github.Timestamp `json:"updated_at,omitempty"`
+	ClosedAt  *github.Timestamp `json:"closed_at,omitempty"`
+
+	// PullRequest is only set when the issue is a pull request.
+	PullRequest *ghIssuePullRequest `json:"pull_request,omitempty"`
+}
+
+// ghLabel is a label of a GitHub issue or pull request.
+type ghLabel struct {
+	Name *string `json:"name,omitempty"`
+}
+
+// ghIssuePullRequest holds the pull request specific fields of a ghIssue.
+type ghIssuePullRequest struct {
+	MergedAt *github.Timestamp `json:"merged_at,omitempty"`
+}
+
+// ghIssuesPage is a page of issues of a GitHub repository.
+type ghIssuesPage struct {
+	issues []ghIssue
+
+	// nextPage is the number of the next page, or 0 if this is the last one.
+	nextPage int
+}
+
+// crawlIssues inserts, or updates, the issues and pull requests of a github
+// repository into the database. Only those updated since the last crawl are
+// fetched.
+func (g *gitHubCrawler) crawlIssues(ghRepoID int64, repo *ghRepository) bool {
+	var lastUpdate *time.Time
+	err := g.db.QueryRow(
+		`SELECT MAX(updated_at)
+		 FROM (SELECT updated_at FROM gh_issues WHERE gh_repository_id=$1
+		       UNION ALL
+		       SELECT updated_at FROM gh_pull_requests WHERE gh_repository_id=$1) AS t`,
+		ghRepoID).Scan(&lastUpdate)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+	glog.Infof("crawl issues of repository: %s", *repo.Name)
+
+	var since string
+	if lastUpdate != nil {
+		since = formatTime(lastUpdate)
+	}
+
+	for page := 1; page != 0; {
+		tmp := g.call(false, g.fetchIssues, *repo.Owner.Login, *repo.Name, page, since)
+		var issues ghIssuesPage
+		switch tmp.(type) {
+		case ghIssuesPage:
+			issues = tmp.(ghIssuesPage)
+		default:
+			glog.Error("invalid function return type")
+			return false
+		}
+
+		for i := range issues.issues {
+			issue := &issues.issues[i]
+			if issue.Number == nil || issue.State == nil {
+				glog.Error("'issue' has nil Number or State field")
+				continue
+			}
+
+			if !g.insertOrUpdateGhIssue(ghRepoID, issue) {
+				return false
+			}
+		}
+
+		page = issues.nextPage
+	}
+
+	return true
+}
+
+// insertOrUpdateGhIssue inserts, or updates, a github issue into gh_issues
+// table, or into gh_pull_requests table if it is a pull request, and replaces
+// its labels.
+func (g *gitHubCrawler) insertOrUpdateGhIssue(ghRepoID int64, issue *ghIssue) bool {
+	table, labelsTable, labelsKey := "gh_issues", "gh_issue_labels", "gh_issue_id"
+	if issue.PullRequest != nil {
+		table, labelsTable, labelsKey = "gh_pull_requests", "gh_pull_request_labels", "gh_pull_request_id"
+	}
+
+	// the author of an issue is unknown when its account has been deleted
+	var ghUserID *int64
+	if issue.User != nil && issue.User.ID != nil && issue.User.Login != nil {
+		id := g.ensureGhUser(issue.User)
+		if id <= 0 {
+			return false
+		}
+		ghUserID = &id
+	}
+
+	fields := []string{
+		"gh_repository_id",
+		"gh_user_id",
+		"github_id",
+		"number",
+		"state",
+		"title",
+		"comments_count",
+		"created_at",
+		"updated_at",
+		"closed_at",
+	}
+	values := []interface{}{
+		ghRepoID,
+		ghUserID,
+		issue.ID,
+		issue.Number,
+		issue.State,
+		issue.Title,
+		issue.Comments,
+		formatTimestamp(issue.CreatedAt),
+		formatTimestamp(issue.UpdatedAt),
+		formatNullTimestamp(issue.ClosedAt),
+	}
+	if issue.PullRequest != nil {
+		fields = append(fields, "merged_at")
+		values = append(values, formatNullTimestamp(issue.PullRequest.MergedAt))
+	}
+
+	var id int64
+	err := g.db.QueryRow(
+		fmt.Sprintf("SELECT id FROM %s WHERE gh_repository_id=$1 AND number=$2", table),
+		ghRepoID, *issue.Number).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		err = g.db.QueryRow(genInsQuery(table, fields...)+" RETURNING id", values...).Scan(&id)
+	case err == nil:
+		_, err = g.db.Exec(genUpdateQuery(table, int(id), fields...), values...)
+	}
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	tx, err := g.db.Begin()
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s=$1", labelsTable, labelsKey), id); err != nil {
+		glog.Error(err)
+		tx.Rollback()
+		return false
+	}
+
+	query := genInsQuery(labelsTable, labelsKey, "name")
+	for _, label := range issue.Labels {
+		if label.Name == nil {
+			continue
+		}
+
+		if _, err := tx.Exec(query, id, *label.Name); err != nil {
+			glog.Error(err)
+			tx.Rollback()
+			return false
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// fetchIssues fetches a page of the issues and pull requests of a repository,
+// least recently updated first.
+//
+// args expects 4 values:
+// - owner: the repository owner
+// - repo: the repository name
+// - page: the page number, starting at 1
+// - since: the time of the least recent update to fetch, empty to fetch all
+//
+// It returns a ghIssuesPage.
+func (g *gitHubCrawler) fetchIssues(args ...interface{}) (interface{}, error) {
+	if len(args) != 4 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	owner, repo, page, err := repoPageArgs(args[:3]...)
+	if err != nil {
+		return nil, err
+	}
+
+	var since string
+	switch args[3].(type) {
+	case string:
+		since = args[3].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[3]))
+		return nil, errInvalidParamType
+	}
+
+	params := url.Values{}
+	params.Set("state", "all")
+	params.Set("sort", "updated")
+	params.Set("direction", "asc")
+	if since != "" {
+		params.Set("since", since)
+	}
+	params.Set("per_page", "100")
+	params.Set("page", fmt.Sprint(page))
+
+	urlStr := fmt.Sprintf("%v/issues?%s", ghRepoURL(owner, repo), params.Encode())
+	req, err := g.client.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	var issues []ghIssue
+	resp, err := g.client.Do(req, &issues)
+	if err != nil {
+		glog.Error(err)
+		return nil, g.genAPICallFuncError(resp, err)
+	}
+
+	return ghIssuesPage{issues: issues, nextPage: resp.NextPage}, nil
+}
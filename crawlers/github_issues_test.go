@@ -0,0 +1,75 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFetchIssues(t *testing.T) {
+	g, closeFn := newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		expectRequest(t, r, "GET", "/repos/DevMine/crawld/issues")
+		q := r.URL.Query()
+		if page := q.Get("page"); page != "2" {
+			t.Errorf("page: expected '2', found '%s'", page)
+		}
+		if q.Get("state") != "all" || q.Get("sort") != "updated" || q.Get("direction") != "asc" {
+			t.Errorf("unexpected query: %v", r.URL.RawQuery)
+		}
+		if since := q.Get("since"); since != "2015-03-14T15:09:26Z" {
+			t.Errorf("since: expected '2015-03-14T15:09:26Z', found %q", since)
+		}
+		fmt.Fprint(w, `[
+			{"number": 1, "state": "open", "user": {"id": 1, "login": "gopher"},
+			 "labels": [{"name": "bug"}], "comments": 3},
+			{"number": 2, "state": "closed", "closed_at": "2015-03-15T10:00:00Z",
+			 "pull_request": {"merged_at": "2015-03-15T10:00:00Z"}}
+		]`)
+	})
+	defer closeFn()
+
+	tmp, err := g.fetchIssues("DevMine", "crawld", 2, "2015-03-14T15:09:26Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues := tmp.(ghIssuesPage)
+	if len(issues.issues) != 2 {
+		t.Fatalf("fetchIssues: expected 2 issues, found %d", len(issues.issues))
+	}
+
+	issue := issues.issues[0]
+	if issue.PullRequest != nil {
+		t.Error("issue #1: expected an issue, found a pull request")
+	}
+	if len(issue.Labels) != 1 || *issue.Labels[0].Name != "bug" {
+		t.Errorf("issue #1: expected label 'bug', found %+v", issue.Labels)
+	}
+	if issue.Comments == nil || *issue.Comments != 3 {
+		t.Errorf("issue #1: expected 3 comments, found %v", issue.Comments)
+	}
+
+	pr := issues.issues[1]
+	if pr.PullRequest == nil {
+		t.Fatal("issue #2: expected a pull request, found an issue")
+	}
+	expected := time.Date(2015, time.March, 15, 10, 0, 0, 0, time.UTC)
+	if pr.PullRequest.MergedAt == nil || !pr.PullRequest.MergedAt.Equal(expected) {
+		t.Errorf("issue #2 merged_at: expected %v, found %v", expected, pr.PullRequest.MergedAt)
+	}
+}
+
+func TestFetchIssuesInvalidArgs(t *testing.T) {
+	g := &gitHubCrawler{}
+	if _, err := g.fetchIssues("DevMine", "crawld", 1); err != errInvalidArgs {
+		t.Errorf("fetchIssues: expected errInvalidArgs, found %v", err)
+	}
+	if _, err := g.fetchIssues("DevMine", "crawld", 1, 42); err != errInvalidParamType {
+		t.Errorf("fetchIssues: expected errInvalidParamType, found %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/google/go-github/github"
+)
+
+// ghRelease is a release of a GitHub repository.
+type ghRelease struct {
+	ID          *int              `json:"id,omitempty"`
+	TagName     *string           `json:"tag_name,omitempty"`
+	Name        *string           `json:"name,omitempty"`
+	Draft       *bool             `json:"draft,omitempty"`
+	Prerelease  *bool             `json:"prerelease,omitempty"`
+	CreatedAt   *github.Timestamp `json:"created_at,omitempty"`
+	PublishedAt *github.Timestamp `json:"published_at,omitempty"`
+	Author      *github.User      `json:"author,omitempty"`
+	Assets      []ghReleaseAsset  `json:"assets,omitempty"`
+}
+
+// ghReleaseAsset is a file attached to a GitHub release.
+type ghReleaseAsset struct {
+	ID            *int    `json:"id,omitempty"`
+	Name          *string `json:"name,omitempty"`
+	ContentType   *string `json:"content_type,omitempty"`
+	Size          *int    `json:"size,omitempty"`
+	DownloadCount *int    `json:"download_count,omitempty"`
+}
+
+// ghTag is a tag of a GitHub repository.
+type ghTag struct {
+	Name   *string `json:"name,omitempty"`
+	Commit *struct {
+		SHA *string `json:"sha,omitempty"`
+	} `json:"commit,omitempty"`
+}
+
+// ghReleasesPage is a page of releases of a GitHub repository.
+type ghReleasesPage struct {
+	releases []ghRelease
+
+	// nextPage is the number of the next page, or 0 if this is the last one.
+	nextPage int
+}
+
+// ghTagsPage is a page of tags of a GitHub repository.
+type ghTagsPage struct {
+	tags []ghTag
+
+	// nextPage is the number of the next page, or 0 if this is the last one.
+	nextPage int
+}
+
+// crawlReleases replaces the releases, along with their assets, and the tags
+// of a github repository in the database. They are all fetched again on each
+// crawl since the download counts of the assets keep changing.
+func (g *gitHubCrawler) crawlReleases(ghRepoID int64, repo *ghRepository) bool {
+	glog.Infof("crawl releases of repository: %s", *repo.Name)
+
+	var releases []ghRelease
+	var authorIDs []*int64
+	for page := 1; page != 0; {
+		tmp := g.call(false, g.fetchReleases, *repo.Owner.Login, *repo.Name, page)
+		var releasesPage ghReleasesPage
+		switch tmp.(type) {
+		case ghReleasesPage:
+			releasesPage = tmp.(ghReleasesPage)
+		default:
+			glog.Error("invalid function return type")
+			return false
+		}
+
+		for _, r := range releasesPage.releases {
+			if r.ID == nil || r.TagName == nil {
+				glog.Error("'release' has nil ID or TagName field")
+				continue
+			}
+
+			var authorID *int64
+			if r.Author != nil && r.Author.ID != nil && r.Author.Login != nil {
+				id := g.ensureGhUser(r.Author)
+				if id <= 0 {
+					return false
+				}
+				authorID = &id
+			}
+
+			releases = append(releases, r)
+			authorIDs = append(authorIDs, authorID)
+		}
+
+		page = releasesPage.nextPage
+	}
+
+	var tags []ghTag
+	for page := 1; page != 0; {
+		tmp := g.call(false, g.fetchTags, *repo.Owner.Login, *repo.Name, page)
+		var tagsPage ghTagsPage
+		switch tmp.(type) {
+		case ghTagsPage:
+			tagsPage = tmp.(ghTagsPage)
+		default:
+			glog.Error("invalid function return type")
+			return false
+		}
+
+		for _, t := range tagsPage.tags {
+			if t.Name == nil {
+				glog.Error("'tag' has nil Name field")
+				continue
+			}
+			tags = append(tags, t)
+		}
+
+		page = tagsPage.nextPage
+	}
+
+	tx, err := g.db.Begin()
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	deletions := []string{
+		`DELETE FROM gh_release_assets
+		 WHERE gh_release_id IN (SELECT id FROM gh_releases WHERE gh_repository_id=$1)`,
+		"DELETE FROM gh_releases WHERE gh_repository_id=$1",
+		"DELETE FROM gh_tags WHERE gh_repository_id=$1",
+	}
+	for _, query := range deletions {
+		if _, err := tx.Exec(query, ghRepoID); err != nil {
+			glog.Error(err)
+			tx.Rollback()
+			return false
+		}
+	}
+
+	releaseQuery := genInsQuery("gh_releases",
+		"gh_repository_id",
+		"gh_user_id",
+		"github_id",
+		"tag_name",
+		"name",
+		"draft",
+		"prerelease",
+		"created_at",
+		"published_at")
+	assetQuery := genInsQuery("gh_release_assets",
+		"gh_release_id",
+		"github_id",
+		"name",
+		"content_type",
+		"size",
+		"download_count")
+	for i, r := range releases {
+		var ghReleaseID int64
+		err := tx.QueryRow(releaseQuery+" RETURNING id",
+			ghRepoID,
+			authorIDs[i],
+			r.ID,
+			r.TagName,
+			r.Name,
+			r.Draft,
+			r.Prerelease,
+			formatNullTimestamp(r.CreatedAt),
+			formatNullTimestamp(r.PublishedAt)).Scan(&ghReleaseID)
+		if err != nil {
+			glog.Error(err)
+			tx.Rollback()
+			return false
+		}
+
+		for _, a := range r.Assets {
+			_, err := tx.Exec(assetQuery, ghReleaseID, a.ID, a.Name, a.ContentType, a.Size, a.DownloadCount)
+			if err != nil {
+				glog.Error(err)
+				tx.Rollback()
+				return false
+			}
+		}
+	}
+
+	tagQuery := genInsQuery("gh_tags", "gh_repository_id", "name", "commit_sha")
+	for _, t := range tags {
+		var sha *string
+		if t.Commit != nil {
+			sha = t.Commit.SHA
+		}
+
+		if _, err := tx.Exec(tagQuery, ghRepoID, t.Name, sha); err != nil {
+			glog.Error(err)
+			tx.Rollback()
+			return false
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// fetchReleases fetches a page of the releases of a repository, most recent
+// first. Draft releases are only listed to the users that can push to the
+// repository.
+//
+// args expects 3 values:
+// - owner: the repository owner
+// - repo: the repository name
+// - page: the page number, starting at 1
+//
+// It returns a ghReleasesPage.
+func (g *gitHubCrawler) fetchReleases(args ...interface{}) (interface{}, error) {
+	owner, repo, page, err := repoPageArgs(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	urlStr := fmt.Sprintf("%v/releases?per_page=100&page=%d", ghRepoURL(owner, repo), page)
+	req, err := g.client.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	var releases []ghRelease
+	resp, err := g.client.Do(req, &releases)
+	if err != nil {
+		glog.Error(err)
+		return nil, g.genAPICallFuncError(resp, err)
+	}
+
+	return ghReleasesPage{releases: releases, nextPage: resp.NextPage}, nil
+}
+
+// fetchTags fetches a page of the tags of a repository, lightweight and
+// annotated ones alike.
+//
+// args expects 3 values:
+// - owner: the repository owner
+// - repo: the repository name
+// - page: the page number, starting at 1
+//
+// It returns a ghTagsPage.
+func (g *gitHubCrawler) fetchTags(args ...interface{}) (interface{}, error) {
+	owner, repo, page, err := repoPageArgs(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	urlStr := fmt.Sprintf("%v/tags?per_page=100&page=%d", ghRepoURL(owner, repo), page)
+	req, err := g.client.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	var tags []ghTag
+	resp, err := g.client.Do(req, &tags)
+	if err != nil {
+		glog.Error(err)
+		return nil, g.genAPICallFuncError(resp, err)
+	}
+
+	return ghTagsPage{tags: tags, nextPage: resp.NextPage}, nil
+}
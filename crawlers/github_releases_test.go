@@ -0,0 +1,63 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestFetchReleases(t *testing.T) {
+	g, closeFn := newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		expectRequest(t, r, "GET", "/repos/DevMine/crawld/releases")
+		if page := r.URL.Query().Get("page"); page != "1" {
+			t.Errorf("page: expected '1', found '%s'", page)
+		}
+		fmt.Fprint(w, `[{"id": 1, "tag_name": "v1.0.0", "name": "1.0", "draft": false, "prerelease": true,
+			"published_at": "2015-03-14T15:09:26Z", "author": {"id": 1, "login": "gopher"},
+			"assets": [{"id": 2, "name": "crawld.tar.gz", "size": 1024, "download_count": 42}]}]`)
+	})
+	defer closeFn()
+
+	tmp, err := g.fetchReleases("DevMine", "crawld", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	releases := tmp.(ghReleasesPage)
+	if len(releases.releases) != 1 {
+		t.Fatalf("fetchReleases: expected 1 release, found %d", len(releases.releases))
+	}
+
+	r := releases.releases[0]
+	if *r.TagName != "v1.0.0" || !*r.Prerelease || *r.Author.Login != "gopher" {
+		t.Errorf("fetchReleases: unexpected release %+v", r)
+	}
+	if len(r.Assets) != 1 || *r.Assets[0].Size != 1024 || *r.Assets[0].DownloadCount != 42 {
+		t.Errorf("fetchReleases: unexpected assets %+v", r.Assets)
+	}
+}
+
+func TestFetchTags(t *testing.T) {
+	g, closeFn := newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		expectRequest(t, r, "GET", "/repos/DevMine/crawld/tags")
+		fmt.Fprint(w, `[{"name": "v1.0.0", "commit": {"sha": "c5b97d5ae6c19d5c5df71a34c7fbeeda2479ccbc"}}]`)
+	})
+	defer closeFn()
+
+	tmp, err := g.fetchTags("DevMine", "crawld", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags := tmp.(ghTagsPage)
+	if len(tags.tags) != 1 || *tags.tags[0].Name != "v1.0.0" {
+		t.Fatalf("fetchTags: expected tag v1.0.0, found %+v", tags.tags)
+	}
+	if sha := *tags.tags[0].Commit.SHA; sha != "c5b97d5ae6c19d5c5df71a34c7fbeeda2479ccbc" {
+		t.Errorf("fetchTags: unexpected commit sha %q", sha)
+	}
+}
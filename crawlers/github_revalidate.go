@@ -0,0 +1,113 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// revalidateRepositories fetches again all the github repositories of the
+// database that are not gone. Repositories that have been deleted, or that
+// are unavailable for legal reasons, are marked as gone whereas renamed and
+// transferred ones are updated, so that the fetcher moves their clones.
+func (g *gitHubCrawler) revalidateRepositories() {
+	glog.Info("revalidate github repositories")
+
+	var lastID int64
+	for {
+		rows, err := g.db.Query(
+			`SELECT gh.id, gh.repository_id, gh.full_name
+			 FROM gh_repositories gh
+			 JOIN repositories r ON r.id = gh.repository_id
+			 WHERE r.gone_at IS NULL AND gh.id > $1
+			 ORDER BY gh.id
+			 LIMIT 100`, lastID)
+		if err != nil {
+			glog.Error(err)
+			return
+		}
+
+		type storedRepo struct {
+			repoID   int64
+			fullName string
+		}
+		var repos []storedRepo
+		for rows.Next() {
+			var r storedRepo
+			if err := rows.Scan(&lastID, &r.repoID, &r.fullName); err != nil {
+				glog.Error(err)
+				continue
+			}
+			repos = append(repos, r)
+		}
+		rows.Close()
+
+		if len(repos) == 0 {
+			return
+		}
+
+		for _, r := range repos {
+			g.revalidateRepository(r.repoID, r.fullName)
+		}
+	}
+}
+
+// revalidateRepository fetches again the github repository identified by
+// fullName, ie owner/name, whose id in repositories table is repoID.
+func (g *gitHubCrawler) revalidateRepository(repoID int64, fullName string) {
+	parts := strings.SplitN(fullName, "/", 2)
+	if len(parts) != 2 {
+		glog.Errorf("invalid repository full name: %s", fullName)
+		return
+	}
+	owner, name := parts[0], parts[1]
+
+	tmp, err := g.callErr(false, g.fetchRepository, owner, name)
+	switch err {
+	case errNotModified:
+		return
+	case errGone:
+		glog.Infof("repository %s is gone", fullName)
+		g.markRepoGone(repoID)
+		return
+	}
+
+	repo, ok := tmp.(*ghRepository)
+	if !ok {
+		glog.Error("invalid fetched repository")
+		return
+	}
+	if err := verifyRepo(&repo.Repository); err != nil {
+		glog.Error(err)
+		return
+	}
+
+	if repo.FullName != nil && *repo.FullName != fullName {
+		glog.Infof("repository %s has moved to %s", fullName, *repo.FullName)
+	}
+
+	if g.insertOrUpdateRepo(repo, nil) {
+		g.etags.commit(ghRepoURL(owner, name))
+	}
+}
+
+// markRepoGone marks the repository identified by repoID in repositories
+// table as gone, so that the fetcher skips it. Its clone is kept.
+func (g *gitHubCrawler) markRepoGone(repoID int64) bool {
+	now := time.Now()
+	_, err := g.db.Exec(
+		`UPDATE repositories
+		 SET gone_at = $1
+		 WHERE id = $2 AND gone_at IS NULL`, formatTime(&now), repoID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
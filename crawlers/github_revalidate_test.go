@@ -0,0 +1,102 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestFetchRepositoryMoved(t *testing.T) {
+	g, closeFn := newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/DevMine/crawld" {
+			http.Redirect(w, r, "/repositories/1", http.StatusMovedPermanently)
+			return
+		}
+		expectRequest(t, r, "GET", "/repositories/1")
+		fmt.Fprint(w, `{"id": 1, "name": "crawler", "full_name": "gopher/crawler"}`)
+	})
+	defer closeFn()
+
+	tmp, err := g.fetchRepository("DevMine", "crawld")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := tmp.(*ghRepository)
+	if repo.FullName == nil || *repo.FullName != "gopher/crawler" {
+		t.Errorf("fetchRepository: expected 'gopher/crawler', found %v", repo.FullName)
+	}
+}
+
+func TestFetchRepositoryMovedClonePath(t *testing.T) {
+	g, closeFn := newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/DevMine/crawld" {
+			http.Redirect(w, r, "/repositories/1", http.StatusMovedPermanently)
+			return
+		}
+		expectRequest(t, r, "GET", "/repositories/1")
+		fmt.Fprint(w, `{"id": 1, "name": "crawler", "full_name": "gopher/crawler",
+			"language": "Go", "owner": {"login": "gopher"}}`)
+	})
+	defer closeFn()
+
+	tmp, err := g.fetchRepository("DevMine", "crawld")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := tmp.(*ghRepository)
+	if got, want := ghClonePath(&repo.Repository), "go/gopher/crawler"; got != want {
+		t.Errorf("ghClonePath: expected %q, found %q", want, got)
+	}
+
+	// the clone path stored before the move must differ from the new one so
+	// that the previous clone path is recorded and the clone is moved
+	lang, owner, name := "Go", "DevMine", "crawld"
+	old := &github.Repository{Language: &lang, Owner: &github.User{Login: &owner}, Name: &name}
+	if ghClonePath(old) == ghClonePath(&repo.Repository) {
+		t.Errorf("ghClonePath: expected the clone path to change, found %q", ghClonePath(old))
+	}
+}
+
+func TestFetchRepositoryGone(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusUnavailableForLegalReasons} {
+		g, closeFn := newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+			expectRequest(t, r, "GET", "/repos/DevMine/crawld")
+			w.WriteHeader(status)
+			fmt.Fprint(w, `{"message": "gone"}`)
+		})
+
+		if _, err := g.fetchRepository("DevMine", "crawld"); err != errGone {
+			t.Errorf("fetchRepository (status %d): expected errGone, found %v", status, err)
+		}
+		closeFn()
+	}
+}
+
+func TestGenAPICallFuncErrorNotFound(t *testing.T) {
+	g, closeFn := newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	defer closeFn()
+
+	req, err := g.client.NewRequest("GET", "repos/DevMine/crawld/contributors", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := g.client.Do(req, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if err = g.genAPICallFuncError(resp, err); err == nil || err == errGone {
+		t.Errorf("genAPICallFuncError: expected the generic error, found %v", err)
+	}
+}
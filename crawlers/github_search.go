@@ -0,0 +1,119 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// searchResultsCap is the maximum number of results the GitHub search API
+	// gives access to for a query.
+	searchResultsCap = 1000
+
+	// searchDateFormat is the format of dates in search queries.
+	searchDateFormat = "2006-01-02"
+
+	// day is the duration of a day.
+	day = 24 * time.Hour
+)
+
+// gitHubLaunchDate is a date before the creation of the first GitHub
+// repository.
+var gitHubLaunchDate = time.Date(2007, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+// searchSlice is a slice of the repositories of a language, identified by a
+// range of creation dates and, optionally, a range of stars.
+type searchSlice struct {
+	lang string
+
+	// from and to are the first and the last creation days of the range.
+	from, to time.Time
+
+	// minStars and maxStars bound the number of stars of the repositories.
+	// Stars are not part of the query when maxStars is negative.
+	minStars, maxStars int
+}
+
+// newSearchSlice creates a slice of all the repositories of a language.
+func newSearchSlice(lang string) searchSlice {
+	today := time.Now().UTC().Truncate(day)
+	return searchSlice{lang: lang, from: gitHubLaunchDate, to: today, maxStars: -1}
+}
+
+// query returns the search query corresponding to the slice.
+func (s searchSlice) query() string {
+	q := fmt.Sprintf("language:%s created:%s..%s", s.lang,
+		s.from.Format(searchDateFormat), s.to.Format(searchDateFormat))
+	if s.maxStars >= 0 {
+		q += fmt.Sprintf(" stars:%d..%d", s.minStars, s.maxStars)
+	}
+	return q
+}
+
+// splitDates splits the slice in two halves of creation dates. The boolean is
+// false if the slice covers a single day and cannot be split.
+func (s searchSlice) splitDates() (searchSlice, searchSlice, bool) {
+	days := int(s.to.Sub(s.from) / day)
+	if days < 1 {
+		return s, s, false
+	}
+
+	first, second := s, s
+	first.to = s.from.Add(time.Duration(days/2) * day)
+	second.from = first.to.Add(day)
+	return first, second, true
+}
+
+// splitStars splits the slice in two halves of stars range. The boolean is
+// false if the slice covers a single number of stars and cannot be split.
+// maxStars must be set beforehand.
+func (s searchSlice) splitStars() (searchSlice, searchSlice, bool) {
+	if s.maxStars <= s.minStars {
+		return s, s, false
+	}
+
+	first, second := s, s
+	first.maxStars = s.minStars + (s.maxStars-s.minStars)/2
+	second.minStars = first.maxStars + 1
+	return first, second, true
+}
+
+// crawlSearchSlice crawls the repositories of a search slice. If the slice
+// has too many repositories for the search API, it is recursively split by
+// creation dates and then, if enabled, by stars, until each slice has less
+// repositories than the cap of the search API.
+// It returns false once the limit is reached.
+func (g *gitHubCrawler) crawlSearchSlice(s searchSlice, n *int64, seen map[int]bool) bool {
+	query := g.searchQuery(s.query())
+	results, ok := g.searchRepositories(query, 1)
+	if !ok {
+		return true
+	}
+
+	if results.total >= searchResultsCap {
+		first, second, ok := s.splitDates()
+		if !ok && g.SearchStarsSlicing && len(results.repos) > 0 {
+			// results are sorted by stars: the first one has the most
+			if s.maxStars < 0 && results.repos[0].StargazersCount != nil {
+				s.maxStars = *results.repos[0].StargazersCount
+			}
+			first, second, ok = s.splitStars()
+		}
+
+		if ok {
+			glog.Infof("search query %q has %d results => splitting it", query, results.total)
+			return g.crawlSearchSlice(first, n, seen) && g.crawlSearchSlice(second, n, seen)
+		}
+
+		glog.Warningf("search query %q has %d results, only the first %d are crawled",
+			query, results.total, searchResultsCap)
+	}
+
+	return g.crawlSearchResults(query, results, n, seen)
+}
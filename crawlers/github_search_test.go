@@ -0,0 +1,87 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSearchSliceSplitDates(t *testing.T) {
+	s := searchSlice{
+		lang:     "go",
+		from:     time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC),
+		to:       time.Date(2015, time.January, 4, 0, 0, 0, 0, time.UTC),
+		maxStars: -1,
+	}
+
+	first, second, ok := s.splitDates()
+	if !ok {
+		t.Fatal("splitDates: expected the slice to be split")
+	}
+
+	if q := first.query(); q != "language:go created:2015-01-01..2015-01-02" {
+		t.Errorf("first.query(): found %q", q)
+	}
+	if q := second.query(); q != "language:go created:2015-01-03..2015-01-04" {
+		t.Errorf("second.query(): found %q", q)
+	}
+
+	first, _, _ = first.splitDates()
+	if _, _, ok := first.splitDates(); ok {
+		t.Errorf("splitDates: a single day slice (%q) cannot be split", first.query())
+	}
+}
+
+func TestSearchSliceSplitStars(t *testing.T) {
+	day := time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s := searchSlice{lang: "go", from: day, to: day, minStars: 0, maxStars: 9}
+
+	first, second, ok := s.splitStars()
+	if !ok {
+		t.Fatal("splitStars: expected the slice to be split")
+	}
+
+	if q := first.query(); q != "language:go created:2015-01-01..2015-01-01 stars:0..4" {
+		t.Errorf("first.query(): found %q", q)
+	}
+	if q := second.query(); q != "language:go created:2015-01-01..2015-01-01 stars:5..9" {
+		t.Errorf("second.query(): found %q", q)
+	}
+
+	s.maxStars = 0
+	if _, _, ok := s.splitStars(); ok {
+		t.Error("splitStars: a single star count slice cannot be split")
+	}
+}
+
+func TestFetchTopRepositories(t *testing.T) {
+	g, closeFn := newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		expectRequest(t, r, "GET", "/search/repositories")
+		if q := r.URL.Query(); q.Get("q") != "language:go" || q.Get("sort") != "stars" || q.Get("page") != "2" {
+			t.Errorf("unexpected query: %v", q)
+		}
+		fmt.Fprint(w, `{"total_count": 1, "items": [{"id": 1, "name": "crawld",
+			"topics": ["crawler"], "license": {"key": "mit", "spdx_id": "MIT"}, "archived": true}]}`)
+	})
+	defer closeFn()
+
+	tmp, err := g.fetchTopRepositories("language:go", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := tmp.(ghRepositoriesPage)
+	if results.total != 1 || len(results.repos) != 1 {
+		t.Fatalf("fetchTopRepositories: expected 1 result, found %d (total: %d)", len(results.repos), results.total)
+	}
+
+	repo := results.repos[0]
+	if *repo.Name != "crawld" || *repo.License.SPDXID != "MIT" || !*repo.Archived || len(repo.Topics) != 1 {
+		t.Errorf("fetchTopRepositories: unexpected repository %+v", repo)
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/golang/glog"
+	"github.com/google/go-github/github"
+)
+
+// starMediaType is the media type of the GitHub API that tells when each
+// stargazer starred a repository.
+const starMediaType = "application/vnd.github.v3.star+json"
+
+// ghStargazer is a user who starred a GitHub repository.
+type ghStargazer struct {
+	StarredAt *github.Timestamp `json:"starred_at,omitempty"`
+	User      *github.User      `json:"user,omitempty"`
+}
+
+// ghStargazersPage is a page of stargazers of a GitHub repository.
+type ghStargazersPage struct {
+	stargazers []ghStargazer
+
+	// nextPage is the number of the next page, or 0 if this is the last one.
+	nextPage int
+}
+
+// ghWatchersPage is a page of watchers of a GitHub repository.
+type ghWatchersPage struct {
+	users []github.User
+
+	// nextPage is the number of the next page, or 0 if this is the last one.
+	nextPage int
+}
+
+// crawlStargazers inserts the stargazers of a github repository into the
+// database, along with the time at which they starred it.
+// Stargazers are listed oldest first, so only the new ones are fetched on
+// re-crawls. Stargazers that removed their star are kept.
+func (g *gitHubCrawler) crawlStargazers(ghRepoID int64, repo *ghRepository) bool {
+	var stored int
+	err := g.db.QueryRow("SELECT COUNT(*) FROM gh_stargazers WHERE gh_repository_id=$1", ghRepoID).Scan(&stored)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if repo.StargazersCount != nil && *repo.StargazersCount == stored {
+		return true
+	}
+	glog.Infof("crawl stargazers of repository: %s", *repo.Name)
+
+	// the last page of stored stargazers is fetched again since removed
+	// stars shift the following ones to the previous pages
+	page := stored / 100
+	if page < 1 {
+		page = 1
+	}
+
+	for page != 0 {
+		tmp := g.call(false, g.fetchStargazers, *repo.Owner.Login, *repo.Name, page)
+		var stargazers ghStargazersPage
+		switch tmp.(type) {
+		case ghStargazersPage:
+			stargazers = tmp.(ghStargazersPage)
+		default:
+			glog.Error("invalid function return type")
+			return false
+		}
+
+		for _, s := range stargazers.stargazers {
+			if s.User == nil || s.User.ID == nil || s.User.Login == nil {
+				glog.Error("'stargazer' has nil User, User.ID or User.Login field")
+				continue
+			}
+
+			ghUserID := g.ensureGhUser(s.User)
+			if ghUserID <= 0 {
+				return false
+			}
+
+			if !g.linkStargazerToGhRepo(ghUserID, ghRepoID, s.StarredAt) {
+				return false
+			}
+		}
+
+		page = stargazers.nextPage
+	}
+
+	return true
+}
+
+// linkStargazerToGhRepo links a github user to a github repository it
+// starred, unless it is already linked.
+func (g *gitHubCrawler) linkStargazerToGhRepo(ghUserID, ghRepoID int64, starredAt *github.Timestamp) bool {
+	var total int
+	err := g.db.QueryRow(
+		`SELECT COUNT(*) AS total
+		 FROM gh_stargazers
+		 WHERE gh_user_id = $1 AND gh_repository_id = $2`, ghUserID, ghRepoID).Scan(&total)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+	if total > 0 {
+		return true
+	}
+
+	query := genInsQuery("gh_stargazers", "gh_repository_id", "gh_user_id", "starred_at")
+	if _, err := g.db.Exec(query, ghRepoID, ghUserID, formatNullTimestamp(starredAt)); err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// crawlWatchers replaces the watchers of a github repository in the
+// database. Watchers are only fetched when their number has changed since
+// the last crawl.
+func (g *gitHubCrawler) crawlWatchers(ghRepoID int64, repo *ghRepository) bool {
+	var stored int
+	err := g.db.QueryRow("SELECT COUNT(*) FROM gh_watchers WHERE gh_repository_id=$1", ghRepoID).Scan(&stored)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	// the REST API names watchers subscribers
+	if repo.SubscribersCount != nil && *repo.SubscribersCount == stored {
+		return true
+	}
+	glog.Infof("crawl watchers of repository: %s", *repo.Name)
+
+	var ghUserIDs []int64
+	for page := 1; page != 0; {
+		tmp := g.call(false, g.fetchWatchers, *repo.Owner.Login, *repo.Name, page)
+		var watchers ghWatchersPage
+		switch tmp.(type) {
+		case ghWatchersPage:
+			watchers = tmp.(ghWatchersPage)
+		default:
+			glog.Error("invalid function return type")
+			return false
+		}
+
+		for i := range watchers.users {
+			user := &watchers.users[i]
+			if user.ID == nil || user.Login == nil {
+				glog.Error("'watcher' has nil ID or Login field")
+				continue
+			}
+
+			ghUserID := g.ensureGhUser(user)
+			if ghUserID <= 0 {
+				return false
+			}
+			ghUserIDs = append(ghUserIDs, ghUserID)
+		}
+
+		page = watchers.nextPage
+	}
+
+	tx, err := g.db.Begin()
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if _, err := tx.Exec("DELETE FROM gh_watchers WHERE gh_repository_id=$1", ghRepoID); err != nil {
+		glog.Error(err)
+		tx.Rollback()
+		return false
+	}
+
+	query := genInsQuery("gh_watchers", "gh_repository_id", "gh_user_id")
+	for _, ghUserID := range ghUserIDs {
+		if _, err := tx.Exec(query, ghRepoID, ghUserID); err != nil {
+			glog.Error(err)
+			tx.Rollback()
+			return false
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// ensureGhUser returns the id of a github user in gh_users table. If the user
+// has never been crawled, it is inserted with the little information known
+// about it, without fetching it, since stargazers and watchers are far too
+// many. Its other fields are filled if it is crawled later on.
+// If an error occurs, -1 is returned.
+func (g *gitHubCrawler) ensureGhUser(user *github.User) int64 {
+	if id := g.getGhUserID(user); id > 0 {
+		return int64(id)
+	} else if id < 0 {
+		return -1
+	}
+
+	var userID int64
+	query := genInsQuery("users", "username")
+	if err := g.db.QueryRow(query+" RETURNING id", user.Login).Scan(&userID); err != nil {
+		glog.Error(err)
+		return -1
+	}
+
+	var ghUserID int64
+	query = genInsQuery("gh_users", "user_id", "github_id", "login", "avatar_url", "html_url")
+	err := g.db.QueryRow(query+" RETURNING id", userID, user.ID, user.Login, user.AvatarURL, user.HTMLURL).Scan(&ghUserID)
+	if err != nil {
+		glog.Error(err)
+		return -1
+	}
+
+	return ghUserID
+}
+
+// fetchStargazers fetches a page of the stargazers of a repository, oldest
+// first.
+//
+// args expects 3 values:
+// - owner: the repository owner
+// - repo: the repository name
+// - page: the page number, starting at 1
+//
+// It returns a ghStargazersPage.
+func (g *gitHubCrawler) fetchStargazers(args ...interface{}) (interface{}, error) {
+	owner, repo, page, err := repoPageArgs(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	urlStr := fmt.Sprintf("%v/stargazers?per_page=100&page=%d", ghRepoURL(owner, repo), page)
+	req, err := g.client.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+	req.Header.Set("Accept", starMediaType)
+
+	var stargazers []ghStargazer
+	resp, err := g.client.Do(req, &stargazers)
+	if err != nil {
+		glog.Error(err)
+		return nil, g.genAPICallFuncError(resp, err)
+	}
+
+	return ghStargazersPage{stargazers: stargazers, nextPage: resp.NextPage}, nil
+}
+
+// fetchWatchers fetches a page of the watchers of a repository.
+//
+// args expects 3 values:
+// - owner: the repository owner
+// - repo: the repository name
+// - page: the page number, starting at 1
+//
+// It returns a ghWatchersPage.
+func (g *gitHubCrawler) fetchWatchers(args ...interface{}) (interface{}, error) {
+	owner, repo, page, err := repoPageArgs(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	urlStr := fmt.Sprintf("%v/subscribers?per_page=100&page=%d", ghRepoURL(owner, repo), page)
+	req, err := g.client.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	var users []github.User
+	resp, err := g.client.Do(req, &users)
+	if err != nil {
+		glog.Error(err)
+		return nil, g.genAPICallFuncError(resp, err)
+	}
+
+	return ghWatchersPage{users: users, nextPage: resp.NextPage}, nil
+}
+
+// repoPageArgs checks the arguments of a function that fetches a page of a
+// list related to a repository, ie the owner and the name of the repository
+// and the page number.
+func repoPageArgs(args ...interface{}) (string, string, int, error) {
+	if len(args) != 3 {
+		glog.Error("invalid number of arguments")
+		return "", "", 0, errInvalidArgs
+	}
+
+	var owner string
+	switch args[0].(type) {
+	case string:
+		owner = args[0].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
+		return "", "", 0, errInvalidParamType
+	}
+
+	var repo string
+	switch args[1].(type) {
+	case string:
+		repo = args[1].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[1]))
+		return "", "", 0, errInvalidParamType
+	}
+
+	var page int
+	switch args[2].(type) {
+	case int:
+		page = args[2].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[2]))
+		return "", "", 0, errInvalidParamType
+	}
+
+	return owner, repo, page, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFetchStargazers(t *testing.T) {
+	g, closeFn := newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		expectRequest(t, r, "GET", "/repos/DevMine/crawld/stargazers")
+		if page := r.URL.Query().Get("page"); page != "3" {
+			t.Errorf("page: expected '3', found '%s'", page)
+		}
+		if accept := r.Header.Get("Accept"); accept != starMediaType {
+			t.Errorf("Accept: expected %q, found %q", starMediaType, accept)
+		}
+		fmt.Fprint(w, `[{"starred_at": "2015-03-14T15:09:26Z", "user": {"id": 1, "login": "gopher"}}]`)
+	})
+	defer closeFn()
+
+	tmp, err := g.fetchStargazers("DevMine", "crawld", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stargazers := tmp.(ghStargazersPage)
+	if len(stargazers.stargazers) != 1 {
+		t.Fatalf("fetchStargazers: expected 1 stargazer, found %d", len(stargazers.stargazers))
+	}
+
+	s := stargazers.stargazers[0]
+	if *s.User.Login != "gopher" {
+		t.Errorf("stargazer login: expected 'gopher', found %q", *s.User.Login)
+	}
+	expected := time.Date(2015, time.March, 14, 15, 9, 26, 0, time.UTC)
+	if s.StarredAt == nil || !s.StarredAt.Equal(expected) {
+		t.Errorf("stargazer starred_at: expected %v, found %v", expected, s.StarredAt)
+	}
+	if stargazers.nextPage != 0 {
+		t.Errorf("fetchStargazers: expected no next page, found %d", stargazers.nextPage)
+	}
+}
+
+func TestRepoPageArgs(t *testing.T) {
+	if _, _, _, err := repoPageArgs("DevMine", "crawld"); err != errInvalidArgs {
+		t.Errorf("repoPageArgs: expected errInvalidArgs, found %v", err)
+	}
+	if _, _, _, err := repoPageArgs("DevMine", "crawld", "1"); err != errInvalidParamType {
+		t.Errorf("repoPageArgs: expected errInvalidParamType, found %v", err)
+	}
+
+	owner, repo, page, err := repoPageArgs("DevMine", "crawld", 2)
+	if err != nil || owner != "DevMine" || repo != "crawld" || page != 2 {
+		t.Errorf("repoPageArgs: expected DevMine, crawld, 2, found %s, %s, %d (%v)", owner, repo, page, err)
+	}
+}
@@ -4,7 +4,42 @@
 
 package crawlers
 
-import "testing"
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+// newTestGitHubCrawler creates a GitHub crawler whose API calls are served by
+// handler. It has no database session. The returned function shuts the stub
+// server down.
+func newTestGitHubCrawler(t *testing.T, handler http.HandlerFunc) (*gitHubCrawler, func()) {
+	ts := httptest.NewServer(handler)
+
+	baseURL, err := url.Parse(ts.URL + "/")
+	if err != nil {
+		ts.Close()
+		t.Fatal(err)
+	}
+
+	tokens := newTokenPool(nil)
+	client := github.NewClient(&http.Client{Transport: tokens})
+	client.BaseURL = baseURL
+
+	return &gitHubCrawler{client: client, tokens: tokens}, ts.Close
+}
+
+// expectRequest reports an error if r is not a request of the given method
+// to the given path of the API.
+func expectRequest(t *testing.T, r *http.Request, method, path string) {
+	if r.Method != method || r.URL.Path != path {
+		t.Errorf("unexpected request: %s %v (expected %s %s)", r.Method, r.URL, method, path)
+	}
+}
 
 func TestIsLanguageWanted(t *testing.T) {
 	wantedLangs := []string{"go", "ruby", "java"}
@@ -36,3 +71,34 @@ func TestIsLanguageWanted(t *testing.T) {
 			wantedLangs, prjLangs)
 	}
 }
+
+func TestWantedRepoLanguages(t *testing.T) {
+	var calls int
+	g, closeFn := newTestGitHubCrawler(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		expectRequest(t, r, "GET", "/repos/DevMine/crawld/languages")
+		fmt.Fprint(w, `{"C": 1024, "Go": 42}`)
+	})
+	defer closeFn()
+	g.Languages = []string{"Go"}
+
+	login, name := "DevMine", "crawld"
+	repo := github.Repository{Name: &name, Owner: &github.User{Login: &login}}
+
+	// the languages are not fetched when the primary language is wanted
+	lang := "Go"
+	repo.Language = &lang
+	if langs, ok := g.wantedRepoLanguages(&repo); !ok || langs != nil || calls != 0 {
+		t.Errorf("wantedRepoLanguages: expected no languages fetch, found %v, %v (%d calls)", langs, ok, calls)
+	}
+
+	lang = "C"
+	if langs, ok := g.wantedRepoLanguages(&repo); !ok || langs["Go"] != 42 || calls != 1 {
+		t.Errorf("wantedRepoLanguages: expected the fetched languages, found %v, %v (%d calls)", langs, ok, calls)
+	}
+
+	g.Languages = []string{"Ruby"}
+	if _, ok := g.wantedRepoLanguages(&repo); ok {
+		t.Error("wantedRepoLanguages: expected the repository to be filtered out")
+	}
+}
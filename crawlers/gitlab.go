@@ -0,0 +1,901 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/DevMine/crawld/config"
+)
+
+const (
+	// gitLabDefaultURL is the URL of the public GitLab instance.
+	gitLabDefaultURL = "https://gitlab.com/"
+
+	// gitLabAPIPath is the path to the GitLab REST API, relative to the
+	// instance URL.
+	gitLabAPIPath = "api/v4/"
+)
+
+// gitLabCrawler implements the Crawler interface.
+type gitLabCrawler struct {
+	config.CrawlerConfig
+	*restClient
+
+	db *sql.DB
+}
+
+// ensure that gitLabCrawler implements the Crawler interface
+var _ Crawler = (*gitLabCrawler)(nil)
+
+// glNamespace represents the namespace (user or group) of a GitLab project.
+type glNamespace struct {
+	ID       *int    `json:"id"`
+	Name     *string `json:"name"`
+	Path     *string `json:"path"`
+	Kind     *string `json:"kind"`
+	FullPath *string `json:"full_path"`
+}
+
+// glProject represents a GitLab project.
+type glProject struct {
+	ID                *int         `json:"id"`
+	Name              *string      `json:"name"`
+	Path              *string      `json:"path"`
+	PathWithNamespace *string      `json:"path_with_namespace"`
+	Description       *string      `json:"description"`
+	DefaultBranch     *string      `json:"default_branch"`
+	WebURL            *string      `json:"web_url"`
+	HTTPURLToRepo     *string      `json:"http_url_to_repo"`
+	Namespace         *glNamespace `json:"namespace"`
+	Owner             *glUser      `json:"owner"`
+	ForkedFromProject *glProject   `json:"forked_from_project"`
+	ForksCount        *int         `json:"forks_count"`
+	StarCount         *int         `json:"star_count"`
+	OpenIssuesCount   *int         `json:"open_issues_count"`
+	CreatedAt         *time.Time   `json:"created_at"`
+	LastActivityAt    *time.Time   `json:"last_activity_at"`
+}
+
+// glUser represents a GitLab user.
+type glUser struct {
+	ID           *int       `json:"id"`
+	Username     *string    `json:"username"`
+	Name         *string    `json:"name"`
+	State        *string    `json:"state"`
+	AvatarURL    *string    `json:"avatar_url"`
+	WebURL       *string    `json:"web_url"`
+	Bio          *string    `json:"bio"`
+	Location     *string    `json:"location"`
+	PublicEmail  *string    `json:"public_email"`
+	WebsiteURL   *string    `json:"website_url"`
+	Organization *string    `json:"organization"`
+	CreatedAt    *time.Time `json:"created_at"`
+}
+
+// glGroup represents a GitLab group.
+type glGroup struct {
+	ID          *int       `json:"id"`
+	Name        *string    `json:"name"`
+	Path        *string    `json:"path"`
+	FullPath    *string    `json:"full_path"`
+	Description *string    `json:"description"`
+	Visibility  *string    `json:"visibility"`
+	AvatarURL   *string    `json:"avatar_url"`
+	WebURL      *string    `json:"web_url"`
+	CreatedAt   *time.Time `json:"created_at"`
+}
+
+// newGitLabCrawler creates a new GitLab crawler.
+func newGitLabCrawler(cfg config.CrawlerConfig, db *sql.DB) (*gitLabCrawler, error) {
+	if db == nil {
+		return nil, errors.New("database session cannot be nil")
+	}
+
+	client, err := newGitLabClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gitLabCrawler{CrawlerConfig: cfg, restClient: client, db: db}, nil
+}
+
+// newGitLabClient creates a REST client for the API of the GitLab instance
+// given in the crawler configuration.
+func newGitLabClient(cfg config.CrawlerConfig) (*restClient, error) {
+	baseURL := cfg.BaseURL
+	if len(strings.Trim(baseURL, " ")) == 0 {
+		baseURL = gitLabDefaultURL
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	var authorization string
+	if len(strings.Trim(cfg.OAuthAccessToken, " ")) != 0 {
+		authorization = "Bearer " + cfg.OAuthAccessToken
+	}
+
+	return newRestClient(baseURL+gitLabAPIPath, authorization)
+}
+
+// Crawl implements the Crawl() method of the Crawler interface.
+func (g *gitLabCrawler) Crawl() {
+	n := g.Limit
+	hasLimit := n > 0
+
+	sinceID := g.SinceID
+ResultsLoop:
+	for {
+		tmp := g.call(g.fetchProjects, sinceID)
+		projects, ok := tmp.([]glProject)
+		if !ok || len(projects) == 0 {
+			break
+		}
+
+		for _, project := range projects {
+			if project.ID == nil {
+				glog.Error("'project' has nil ID field")
+				continue
+			}
+			sinceID = *project.ID
+
+			if n == 0 && hasLimit {
+				break ResultsLoop
+			}
+
+			if err := verifyProject(&project); err != nil {
+				glog.Error(err)
+				continue
+			}
+
+			// skip? fork projects
+			if project.ForkedFromProject != nil && !g.Fork {
+				continue
+			}
+
+			langs, ok := g.call(g.fetchProjectLanguages, *project.ID).(map[string]float64)
+			if !ok {
+				continue
+			}
+			if ok, err := isLanguageWanted(g.Languages, langs); err != nil {
+				glog.Error(err)
+				continue
+			} else if !ok {
+				continue
+			}
+
+			// skip when the method fails because the project is not saved
+			// into the DB
+			if !g.insertOrUpdateProject(&project, primaryLanguage(langs)) {
+				continue
+			}
+
+			n--
+		}
+
+		if n <= 0 && hasLimit {
+			break
+		}
+	}
+}
+
+// fetchProjects fetches a page of public GitLab projects, ordered by ID.
+//
+// args expects 1 value:
+// - sinceID: only projects with an ID greater than this value are returned
+//
+// It returns a list of projects.
+func (g *gitLabCrawler) fetchProjects(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var sinceID int
+	switch args[0].(type) {
+	case int:
+		sinceID = args[0].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	params := url.Values{}
+	params.Set("visibility", "public")
+	params.Set("order_by", "id")
+	params.Set("sort", "asc")
+	params.Set("per_page", "100")
+	params.Set("id_after", strconv.Itoa(sinceID))
+
+	var projects []glProject
+	if _, err := g.get("projects", params, &projects); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// fetchProjectLanguages fetches all languages related to a project.
+//
+// args expects 1 value:
+// - projectID: the GitLab project ID
+//
+// It returns a map of languages (map[string]float64, language => percentage).
+func (g *gitLabCrawler) fetchProjectLanguages(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var projectID int
+	switch args[0].(type) {
+	case int:
+		projectID = args[0].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	langs := map[string]float64{}
+	if _, err := g.get(fmt.Sprintf("projects/%d/languages", projectID), nil, &langs); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	return langs, nil
+}
+
+// fetchUser fetches information about a GitLab user.
+//
+// args expects 1 value:
+// - userID: the GitLab user ID
+//
+// It returns a *glUser.
+func (g *gitLabCrawler) fetchUser(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var userID int
+	switch args[0].(type) {
+	case int:
+		userID = args[0].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	user := new(glUser)
+	if _, err := g.get(fmt.Sprintf("users/%d", userID), nil, user); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// fetchUserByUsername fetches information about a GitLab user given its
+// username.
+//
+// args expects 1 value:
+// - username: the GitLab username
+//
+// It returns a *glUser.
+func (g *gitLabCrawler) fetchUserByUsername(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var username string
+	switch args[0].(type) {
+	case string:
+		username = args[0].(string)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected string)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	params := url.Values{}
+	params.Set("username", username)
+
+	var users []glUser
+	if _, err := g.get("users", params, &users); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, errUnavailable
+	}
+
+	return &users[0], nil
+}
+
+// fetchGroup fetches information about a GitLab group.
+//
+// args expects 1 value:
+// - groupID: the GitLab group ID
+//
+// It returns a *glGroup.
+func (g *gitLabCrawler) fetchGroup(args ...interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var groupID int
+	switch args[0].(type) {
+	case int:
+		groupID = args[0].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	params := url.Values{}
+	params.Set("with_projects", "false")
+
+	group := new(glGroup)
+	if _, err := g.get(fmt.Sprintf("groups/%d", groupID), params, group); err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// fetchGroupMembers fetches a page of members of a GitLab group.
+//
+// args expects 2 values:
+// - groupID: the GitLab group ID
+// - page: the page number
+//
+// It returns a glMembersPage.
+func (g *gitLabCrawler) fetchGroupMembers(args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		glog.Error("invalid number of arguments")
+		return nil, errInvalidArgs
+	}
+
+	var groupID int
+	switch args[0].(type) {
+	case int:
+		groupID = args[0].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[0]))
+		return nil, errInvalidParamType
+	}
+
+	var page int
+	switch args[1].(type) {
+	case int:
+		page = args[1].(int)
+	default:
+		glog.Errorf("invalid parameter type (given %v, expected int)", reflect.TypeOf(args[1]))
+		return nil, errInvalidParamType
+	}
+
+	params := url.Values{}
+	params.Set("per_page", "100")
+	params.Set("page", strconv.Itoa(page))
+
+	var members glMembersPage
+	header, err := g.get(fmt.Sprintf("groups/%d/members", groupID), params, &members.users)
+	if err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+	members.nextPage, _ = strconv.Atoi(header.Get("X-Next-Page"))
+
+	return members, nil
+}
+
+// glMembersPage is a page of GitLab group members.
+type glMembersPage struct {
+	users    []glUser
+	nextPage int
+}
+
+// getRepoID returns the repository id of project in repositories table.
+// If project is not in the table, then 0 is returned. If an error occurs, -1
+// is returned.
+func (g *gitLabCrawler) getRepoID(project *glProject) int {
+	if project == nil {
+		glog.Error("'project' arg given is nil")
+		return -1
+	}
+
+	return getRepoIDByCloneURL(g.db, project.HTTPURLToRepo)
+}
+
+// getGlProjectID returns the gitlab project id of project in gl_projects
+// table, given the id of the corresponding repository.
+// If project is not in the table, then 0 is returned. If an error occurs, -1
+// is returned.
+func (g *gitLabCrawler) getGlProjectID(repoID int64) int {
+	var id int
+	err := g.db.QueryRow("SELECT id FROM gl_projects WHERE repository_id=$1", repoID).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// getGlGroupID returns the gitlab group id of group in gl_groups table.
+// If group is not in the table, then 0 is returned. If an error occurs, -1 is
+// returned.
+func (g *gitLabCrawler) getGlGroupID(group *glGroup) int {
+	if group == nil {
+		glog.Error("'group' arg given is nil")
+		return -1
+	}
+
+	var id int
+	err := g.db.QueryRow("SELECT id FROM gl_groups WHERE web_url=$1", group.WebURL).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// getGlUserID returns the gitlab user id of user in gl_users table.
+// If user is not in the table, then 0 is returned. If an error occurs, -1 is
+// returned.
+func (g *gitLabCrawler) getGlUserID(user *glUser) int {
+	if user == nil {
+		glog.Error("'user' arg given is nil")
+		return -1
+	}
+
+	var id int
+	err := g.db.QueryRow("SELECT id FROM gl_users WHERE web_url=$1", user.WebURL).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// getUserID returns the user id of user in users table.
+// If user is not in the table, then 0 is returned. If an error occurs, -1 is
+// returned.
+func (g *gitLabCrawler) getUserID(user *glUser) int {
+	if user == nil {
+		glog.Error("'user' arg given is nil")
+		return -1
+	}
+
+	var id int
+	err := g.db.QueryRow("SELECT user_id FROM gl_users WHERE web_url=$1", user.WebURL).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// insertOrUpdateProject inserts or updates a repository. It also inserts or
+// updates related GitLab project, users, GitLab users and GitLab group (if
+// any).
+func (g *gitLabCrawler) insertOrUpdateProject(project *glProject, lang string) bool {
+	if project == nil {
+		glog.Error("'project' arg given is nil")
+		return false
+	}
+	glog.Infof("insert or update project: %s", *project.PathWithNamespace)
+
+	clonePath := strings.ToLower(filepath.Join(lang, g.baseURL.Host, *project.PathWithNamespace))
+	repoFields := []string{"name", "primary_language", "clone_url", "clone_path", "vcs"}
+
+	var query string
+	if id := g.getRepoID(project); id > 0 {
+		query = genUpdateQuery("repositories", id, repoFields...)
+	} else if id == 0 {
+		query = genInsQuery("repositories", repoFields...)
+	} else {
+		return false
+	}
+
+	var repoID int64
+	err := g.db.QueryRow(query+" RETURNING id",
+		project.Name, lang, project.HTTPURLToRepo, clonePath, "git").Scan(&repoID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if !g.insertOrUpdateGlProject(repoID, project) {
+		return false
+	}
+
+	ns := project.Namespace
+	if *ns.Kind == "group" {
+		return g.insertOrUpdateGlGroup(*ns.ID, repoID)
+	}
+
+	var user *glUser
+	if project.Owner != nil && project.Owner.ID != nil {
+		user = project.Owner
+	} else {
+		tmp := g.call(g.fetchUserByUsername, *ns.Path)
+		switch tmp.(type) {
+		case *glUser:
+			user = tmp.(*glUser)
+		default:
+			glog.Error("invalid function return type")
+			return false
+		}
+	}
+
+	return g.insertOrUpdateUser(*user.ID, repoID, 0)
+}
+
+// insertOrUpdateGlProject inserts, or updates, a gitlab project in the
+// database.
+func (g *gitLabCrawler) insertOrUpdateGlProject(repoID int64, project *glProject) bool {
+	if project == nil {
+		glog.Error("'project' arg given is nil")
+		return false
+	}
+	glog.Infof("insert or update gitlab project: %s", *project.PathWithNamespace)
+
+	glProjectFields := []string{
+		"repository_id",
+		"gitlab_id",
+		"path_with_namespace",
+		"description",
+		"default_branch",
+		"web_url",
+		"fork",
+		"forks_count",
+		"star_count",
+		"open_issues_count",
+		"created_at",
+		"last_activity_at",
+	}
+
+	var query string
+	if id := g.getGlProjectID(repoID); id > 0 {
+		query = genUpdateQuery("gl_projects", id, glProjectFields...)
+	} else if id == 0 {
+		query = genInsQuery("gl_projects", glProjectFields...)
+	} else {
+		return false
+	}
+
+	_, err := g.db.Exec(query,
+		repoID,
+		project.ID,
+		project.PathWithNamespace,
+		project.Description,
+		project.DefaultBranch,
+		project.WebURL,
+		project.ForkedFromProject != nil,
+		project.ForksCount,
+		project.StarCount,
+		project.OpenIssuesCount,
+		formatTime(project.CreatedAt),
+		formatTime(project.LastActivityAt))
+
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// insertOrUpdateGlGroup inserts, or updates, a gitlab group into the
+// database. Members of the group are linked to the given repository.
+func (g *gitLabCrawler) insertOrUpdateGlGroup(groupID int, repoID int64) bool {
+	glog.Infof("insert or update gitlab group: %d", groupID)
+
+	tmp := g.call(g.fetchGroup, groupID)
+	var group *glGroup
+	switch tmp.(type) {
+	case *glGroup:
+		group = tmp.(*glGroup)
+	default:
+		glog.Error("invalid function return type")
+		return false
+	}
+
+	glGroupFields := []string{
+		"gitlab_id",
+		"name",
+		"path",
+		"full_path",
+		"description",
+		"visibility",
+		"avatar_url",
+		"web_url",
+		"created_at",
+	}
+
+	var query string
+	if id := g.getGlGroupID(group); id > 0 {
+		query = genUpdateQuery("gl_groups", id, glGroupFields...)
+	} else if id == 0 {
+		query = genInsQuery("gl_groups", glGroupFields...)
+	} else {
+		return false
+	}
+
+	var glGroupID int64
+	err := g.db.QueryRow(query+" RETURNING id",
+		group.ID,
+		group.Name,
+		group.Path,
+		group.FullPath,
+		group.Description,
+		group.Visibility,
+		group.AvatarURL,
+		group.WebURL,
+		formatTime(group.CreatedAt)).Scan(&glGroupID)
+
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	for page := 1; page != 0; {
+		tmp = g.call(g.fetchGroupMembers, groupID, page)
+		members, ok := tmp.(glMembersPage)
+		if !ok {
+			glog.Error("invalid function return type")
+			return false
+		}
+
+		for _, member := range members.users {
+			if member.ID == nil {
+				glog.Error("'member' has nil ID field")
+				continue
+			}
+			if !g.insertOrUpdateUser(*member.ID, repoID, glGroupID) {
+				return false
+			}
+		}
+
+		page = members.nextPage
+	}
+
+	return true
+}
+
+// insertOrUpdateUser inserts, or updates, a gitlab user into the database.
+func (g *gitLabCrawler) insertOrUpdateUser(glUserID int, repoID int64, groupID int64) bool {
+	glog.Infof("insert or update gitlab user: %d", glUserID)
+
+	if repoID <= 0 {
+		glog.Error("trying to insert a user without linked GitLab project")
+		return false
+	}
+
+	tmp := g.call(g.fetchUser, glUserID)
+	var user *glUser
+	switch tmp.(type) {
+	case *glUser:
+		user = tmp.(*glUser)
+	default:
+		glog.Error("invalid function return type")
+		return false
+	}
+
+	userFields := []string{"username", "name", "email"}
+
+	var query string
+	if id := g.getUserID(user); id > 0 {
+		query = genUpdateQuery("users", id, userFields...)
+	} else if id == 0 {
+		query = genInsQuery("users", userFields...)
+	} else {
+		return false
+	}
+
+	var userID int64
+	err := g.db.QueryRow(query+" RETURNING id", user.Username, user.Name, user.PublicEmail).Scan(&userID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if !linkUserToRepo(g.db, userID, repoID) {
+		return false
+	}
+
+	if !g.insertOrUpdateGlUser(userID, user, groupID) {
+		return false
+	}
+
+	return true
+}
+
+// insertOrUpdateGlUser inserts, or updates, a gitlab user into the database.
+func (g *gitLabCrawler) insertOrUpdateGlUser(userID int64, user *glUser, groupID int64) bool {
+	if user == nil {
+		glog.Error("'user' arg given is nil")
+		return false
+	}
+	glog.Infof("insert or update gitlab user: %s", *user.Username)
+
+	if userID <= 0 {
+		glog.Error("trying to insert a gitlab user but no user ID given")
+		return false
+	}
+
+	glUserFields := []string{
+		"user_id",
+		"gitlab_id",
+		"username",
+		"state",
+		"bio",
+		"location",
+		"public_email",
+		"website_url",
+		"organization",
+		"avatar_url",
+		"web_url",
+		"created_at",
+	}
+
+	var query string
+	if id := g.getGlUserID(user); id > 0 {
+		query = genUpdateQuery("gl_users", id, glUserFields...)
+	} else if id == 0 {
+		query = genInsQuery("gl_users", glUserFields...)
+	} else {
+		return false
+	}
+
+	var glUserID int64
+	err := g.db.QueryRow(query+" RETURNING id",
+		userID,
+		user.ID,
+		user.Username,
+		user.State,
+		user.Bio,
+		user.Location,
+		user.PublicEmail,
+		user.WebsiteURL,
+		user.Organization,
+		user.AvatarURL,
+		user.WebURL,
+		formatTime(user.CreatedAt)).Scan(&glUserID)
+
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if groupID != 0 {
+		if !g.linkGlUserToGlGroup(glUserID, groupID) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isGlUserLinkedToGlGroup checks whether a gitlab user is linked to the given
+// gitlab group or not.
+func (g *gitLabCrawler) isGlUserLinkedToGlGroup(glUserID, groupID int64) bool {
+	row := g.db.QueryRow(
+		`SELECT COUNT(*) AS total
+		 FROM gl_users_groups
+		 WHERE gl_user_id = $1 AND gl_group_id = $2`, glUserID, groupID)
+
+	var total int64
+	if err := row.Scan(&total); err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return total > 0
+}
+
+// linkGlUserToGlGroup links a gitlab user to the given gitlab group.
+func (g *gitLabCrawler) linkGlUserToGlGroup(glUserID, groupID int64) bool {
+	if g.isGlUserLinkedToGlGroup(glUserID, groupID) {
+		return true
+	}
+
+	fields := []string{"gl_user_id", "gl_group_id"}
+
+	query := genInsQuery("gl_users_groups", fields...)
+
+	_, err := g.db.Exec(query, glUserID, groupID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// primaryLanguage returns the language with the biggest share in langs.
+func primaryLanguage(langs map[string]float64) string {
+	var lang string
+	var max float64
+	for k, v := range langs {
+		if v > max || (v == max && k < lang) {
+			lang, max = k, v
+		}
+	}
+	return lang
+}
+
+// verifyProject checks all essential fields of a glProject structure for nil
+// values. An error is returned if one of the essential field is nil.
+func verifyProject(project *glProject) error {
+	if project == nil {
+		return newInvalidStructError("verifyProject: project is nil")
+	}
+
+	var err *invalidStructError
+	if project.ID == nil {
+		err = newInvalidStructError("verifyProject: contains nil fields:").AddField("ID")
+	} else {
+		err = newInvalidStructError(fmt.Sprintf("verifyProject: project #%d contains nil fields: ", *project.ID))
+	}
+
+	if project.Name == nil {
+		err.AddField("Name")
+	}
+
+	if project.PathWithNamespace == nil {
+		err.AddField("PathWithNamespace")
+	}
+
+	if project.HTTPURLToRepo == nil {
+		err.AddField("HTTPURLToRepo")
+	}
+
+	if project.Namespace == nil {
+		err.AddField("Namespace")
+	} else {
+		if project.Namespace.ID == nil {
+			err.AddField("Namespace.ID")
+		}
+		if project.Namespace.Kind == nil {
+			err.AddField("Namespace.Kind")
+		}
+		if project.Namespace.Path == nil {
+			err.AddField("Namespace.Path")
+		}
+	}
+
+	if err.FieldsLen() > 0 {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DevMine/crawld/config"
+)
+
+// newTestGitLabCrawler creates a GitLab crawler that queries the stub server
+// located at baseURL. It has no database session.
+func newTestGitLabCrawler(t *testing.T, baseURL string) *gitLabCrawler {
+	cfg := config.CrawlerConfig{Type: "gitlab", Languages: []string{"go"}, BaseURL: baseURL}
+
+	client, err := newGitLabClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &gitLabCrawler{CrawlerConfig: cfg, restClient: client}
+}
+
+func TestGitLabFetchProjects(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/projects" {
+			http.NotFound(w, r)
+			return
+		}
+		if idAfter := r.URL.Query().Get("id_after"); idAfter != "41" {
+			t.Errorf("id_after: expected '41', found '%s'", idAfter)
+		}
+		fmt.Fprint(w, `[{
+			"id": 42,
+			"name": "crawld",
+			"path_with_namespace": "devmine/crawld",
+			"http_url_to_repo": "https://gitlab.example.com/devmine/crawld.git",
+			"namespace": {"id": 7, "path": "devmine", "kind": "group"}
+		}]`)
+	}))
+	defer ts.Close()
+
+	g := newTestGitLabCrawler(t, ts.URL)
+
+	projects, ok := g.call(g.fetchProjects, 41).([]glProject)
+	if !ok {
+		t.Fatal("fetchProjects: expected a list of projects")
+	}
+
+	if len(projects) != 1 {
+		t.Fatalf("len(projects): expected 1, found %d", len(projects))
+	}
+
+	if err := verifyProject(&projects[0]); err != nil {
+		t.Error(err)
+	}
+
+	if *projects[0].Namespace.Kind != "group" {
+		t.Errorf("namespace.kind: expected 'group', found '%s'", *projects[0].Namespace.Kind)
+	}
+}
+
+func TestGitLabFetchGroupMembers(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/groups/7/members" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"id": 1, "username": "alice"}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"id": 2, "username": "bob"}]`)
+	}))
+	defer ts.Close()
+
+	g := newTestGitLabCrawler(t, ts.URL)
+
+	var usernames []string
+	for page := 1; page != 0; {
+		members, ok := g.call(g.fetchGroupMembers, 7, page).(glMembersPage)
+		if !ok {
+			t.Fatal("fetchGroupMembers: expected a page of members")
+		}
+		for _, u := range members.users {
+			usernames = append(usernames, *u.Username)
+		}
+		page = members.nextPage
+	}
+
+	if len(usernames) != 2 || usernames[0] != "alice" || usernames[1] != "bob" {
+		t.Errorf("members: expected [alice bob], found %v", usernames)
+	}
+}
+
+func TestPrimaryLanguage(t *testing.T) {
+	langs := map[string]float64{
+		"Go":    71.3,
+		"Shell": 20.1,
+		"HTML":  8.6,
+	}
+
+	if lang := primaryLanguage(langs); lang != "Go" {
+		t.Errorf("primaryLanguage(%v): expected 'Go', found '%s'", langs, lang)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// restClient is a minimal client for the JSON REST APIs of code sharing
+// platforms. It keeps track of the time at which the API rate limit is reset
+// so that API calls can be retried once it is.
+type restClient struct {
+	client  *http.Client
+	baseURL *url.URL
+
+	// authorization is the value of the Authorization header sent along
+	// with each request, if any.
+	authorization string
+
+	resetAt time.Time
+}
+
+// newRestClient creates a new REST client for the API located at baseURL.
+// authorization is the value of the Authorization header to send along with
+// each request. It is ignored when empty.
+func newRestClient(baseURL, authorization string) (*restClient, error) {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &restClient{client: http.DefaultClient, baseURL: u, authorization: authorization}, nil
+}
+
+// get performs a GET request on urlStr and decodes the JSON response into v.
+// urlStr is resolved relative to the base URL of the API, which means that
+// absolute URLs (such as pagination links) are used as is. params are added
+// to the query string of the URL.
+// It returns the headers of the response.
+func (c *restClient) get(urlStr string, params url.Values, v interface{}) (http.Header, error) {
+	rel, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	u := c.baseURL.ResolveReference(rel)
+	if len(params) != 0 {
+		q := u.Query()
+		for k, vs := range params {
+			q[k] = vs
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if len(c.authorization) != 0 {
+		req.Header.Set("Authorization", c.authorization)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		c.resetAt = time.Now().Add(time.Minute)
+		if reset, err := strconv.ParseInt(resp.Header.Get("RateLimit-Reset"), 10, 64); err == nil {
+			c.resetAt = time.Unix(reset, 0)
+		} else if after, err := strconv.ParseInt(resp.Header.Get("Retry-After"), 10, 64); err == nil {
+			c.resetAt = time.Now().Add(time.Duration(after) * time.Second)
+		}
+		return nil, errTooManyCall
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden:
+		return nil, errUnavailable
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, fmt.Errorf("GET %s: unexpected status %s", u, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, err
+	}
+
+	return resp.Header, nil
+}
+
+// call shall be used when doing a query on the API. If the query is refused
+// because the rate limit is reached, then this function waits for the
+// appropriate time before retrying the query.
+func (c *restClient) call(fct apiCallFunc, args ...interface{}) interface{} {
+	ret, _ := c.callErr(fct, args...)
+	return ret
+}
+
+// callErr is like call but it also returns the error of the query, which is
+// typically used to tell whether a resource is available or not.
+func (c *restClient) callErr(fct apiCallFunc, args ...interface{}) (interface{}, error) {
+	var ret interface{}
+	var err error
+
+	// gotta wait if rate limit is exceeded
+	for {
+		if ret, err = fct(args...); err != errTooManyCall {
+			break
+		}
+
+		waitTime := c.resetAt.Unix() - time.Now().Unix() + 1
+		if waitTime <= 0 {
+			waitTime = 60
+		}
+		glog.Infof("not enough API calls left => waiting for %d minutes and %d seconds",
+			waitTime/60, waitTime%60)
+		time.Sleep(time.Duration(waitTime) * time.Second)
+	}
+
+	return ret, err
+}
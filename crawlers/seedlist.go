@@ -0,0 +1,255 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"github.com/DevMine/crawld/config"
+)
+
+// seedListCrawler implements the Crawler interface. Instead of querying the
+// API of a code sharing platform, it registers the repositories listed in a
+// local file.
+type seedListCrawler struct {
+	config.CrawlerConfig
+
+	db *sql.DB
+}
+
+// ensure that seedListCrawler implements the Crawler interface
+var _ Crawler = (*seedListCrawler)(nil)
+
+// seedEntry is a repository listed in a seed file. Only CloneURL is
+// mandatory, other fields are guessed from it when empty.
+type seedEntry struct {
+	CloneURL string `json:"clone_url"`
+	URL      string `json:"url"`
+	Name     string `json:"name"`
+	Language string `json:"language"`
+	Owner    string `json:"owner"`
+	VCS      string `json:"vcs"`
+}
+
+// newSeedListCrawler creates a new seed list crawler.
+func newSeedListCrawler(cfg config.CrawlerConfig, db *sql.DB) (*seedListCrawler, error) {
+	if db == nil {
+		return nil, errors.New("database session cannot be nil")
+	}
+
+	if len(strings.Trim(cfg.SeedFile, " ")) == 0 {
+		return nil, errors.New("a seed file is required for the seedlist crawler")
+	}
+
+	return &seedListCrawler{CrawlerConfig: cfg, db: db}, nil
+}
+
+// Crawl implements the Crawl() method of the Crawler interface.
+func (s *seedListCrawler) Crawl() {
+	f, err := os.Open(s.SeedFile)
+	if err != nil {
+		glog.Error(err)
+		return
+	}
+	defer f.Close()
+
+	n := s.Limit
+	hasLimit := n > 0
+
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		if n == 0 && hasLimit {
+			break
+		}
+
+		entry, err := parseSeedLine(scanner.Text())
+		if err != nil {
+			glog.Errorf("%s:%d: %v", s.SeedFile, lineNum, err)
+			continue
+		}
+		if entry == nil {
+			// blank line, comment or CSV header
+			continue
+		}
+
+		// the language filter only applies to entries that have one
+		if len(s.Languages) != 0 && len(entry.Language) != 0 {
+			if ok, err := isLanguageWanted(s.Languages, &entry.Language); err != nil {
+				glog.Error(err)
+				continue
+			} else if !ok {
+				continue
+			}
+		}
+
+		if !s.insertOrUpdateRepo(entry) {
+			continue
+		}
+
+		n--
+	}
+
+	if err := scanner.Err(); err != nil {
+		glog.Error(err)
+	}
+}
+
+// insertOrUpdateRepo inserts or updates the repository described by entry.
+func (s *seedListCrawler) insertOrUpdateRepo(entry *seedEntry) bool {
+	if entry == nil {
+		glog.Error("'entry' arg given is nil")
+		return false
+	}
+	glog.Infof("insert or update repository: %s", entry.CloneURL)
+
+	host, _, err := splitCloneURL(entry.CloneURL)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	clonePath := strings.ToLower(filepath.Join(entry.Language, host, entry.Owner, entry.Name))
+	repoFields := []string{"name", "primary_language", "clone_url", "clone_path", "vcs"}
+
+	var query string
+	if id := getRepoIDByCloneURL(s.db, &entry.CloneURL); id > 0 {
+		query = genUpdateQuery("repositories", id, repoFields...)
+	} else if id == 0 {
+		query = genInsQuery("repositories", repoFields...)
+	} else {
+		return false
+	}
+
+	_, err = s.db.Exec(query, entry.Name, entry.Language, entry.CloneURL, clonePath, entry.VCS)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// parseSeedLine parses a line of a seed file. A line is either a JSON object,
+// a CSV record (clone URL, name, language, owner, VCS; only the clone URL is
+// mandatory) or a plain clone URL. Blank lines, lines starting with '#' and
+// CSV headers are ignored, in which case a nil entry is returned.
+func parseSeedLine(line string) (*seedEntry, error) {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return nil, nil
+	}
+
+	entry := new(seedEntry)
+	switch {
+	case strings.HasPrefix(line, "{"):
+		if err := json.Unmarshal([]byte(line), entry); err != nil {
+			return nil, err
+		}
+		if len(entry.CloneURL) == 0 {
+			entry.CloneURL = entry.URL
+		}
+	case strings.Contains(line, ","):
+		fields, err := csv.NewReader(strings.NewReader(line)).Read()
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range fields {
+			fields[i] = strings.TrimSpace(v)
+		}
+		if f := strings.ToLower(fields[0]); f == "url" || f == "clone_url" {
+			return nil, nil
+		}
+		fields = append(fields, make([]string, 5)...)
+		entry.CloneURL = fields[0]
+		entry.Name = fields[1]
+		entry.Language = fields[2]
+		entry.Owner = fields[3]
+		entry.VCS = fields[4]
+	default:
+		entry.CloneURL = line
+	}
+
+	if len(entry.CloneURL) == 0 {
+		return nil, errors.New("missing clone url")
+	}
+
+	_, p, err := splitCloneURL(entry.CloneURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entry.Name) == 0 {
+		entry.Name = strings.TrimSuffix(path.Base(p), ".git")
+	}
+	if len(entry.Owner) == 0 {
+		if dir := path.Dir(p); dir != "." && dir != "/" {
+			entry.Owner = path.Base(dir)
+		}
+	}
+	if len(entry.Name) == 0 || entry.Name == "." || entry.Name == "/" {
+		return nil, errors.New("cannot guess repository name from " + entry.CloneURL)
+	}
+
+	if len(entry.VCS) == 0 {
+		entry.VCS = guessVCS(entry.CloneURL)
+	}
+	entry.VCS = strings.ToLower(entry.VCS)
+
+	return entry, nil
+}
+
+// splitCloneURL returns the host and the path of a clone URL. Besides regular
+// URLs, it understands the scp-like syntax used by git for SSH URLs
+// (eg: "git@github.com:DevMine/crawld.git").
+func splitCloneURL(cloneURL string) (host, p string, err error) {
+	if !strings.Contains(cloneURL, "://") {
+		if i := strings.Index(cloneURL, ":"); i > 0 {
+			host = cloneURL[:i]
+			if j := strings.LastIndex(host, "@"); j >= 0 {
+				host = host[j+1:]
+			}
+			return host, "/" + strings.TrimPrefix(cloneURL[i+1:], "/"), nil
+		}
+		return "", "", errors.New("invalid clone url: " + cloneURL)
+	}
+
+	u, err := url.Parse(cloneURL)
+	if err != nil {
+		return "", "", err
+	}
+	if len(u.Host) == 0 {
+		return "", "", errors.New("invalid clone url: " + cloneURL)
+	}
+
+	return u.Host, strings.TrimSuffix(u.Path, "/"), nil
+}
+
+// guessVCS guesses the VCS of a repository from its clone URL. It defaults to
+// "git" when there is no hint of another VCS.
+func guessVCS(cloneURL string) string {
+	u := strings.ToLower(cloneURL)
+	switch {
+	case strings.HasSuffix(u, ".git"):
+		return "git"
+	case strings.HasPrefix(u, "svn://"), strings.HasPrefix(u, "svn+ssh://"),
+		strings.Contains(u, "/svn/"), strings.Contains(u, "://svn."):
+		return "svn"
+	case strings.HasPrefix(u, "hg://"), strings.Contains(u, "/hg/"),
+		strings.Contains(u, "://hg."):
+		return "hg"
+	}
+	return "git"
+}
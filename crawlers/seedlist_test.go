@@ -0,0 +1,53 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import "testing"
+
+func TestParseSeedLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want *seedEntry
+	}{
+		{"", nil},
+		{"# curated list", nil},
+		{"url,name,language,owner,vcs", nil},
+		{
+			"https://github.com/DevMine/crawld.git",
+			&seedEntry{CloneURL: "https://github.com/DevMine/crawld.git", Name: "crawld", Owner: "DevMine", VCS: "git"},
+		},
+		{
+			"git@gitlab.com:DevMine/crawld.git",
+			&seedEntry{CloneURL: "git@gitlab.com:DevMine/crawld.git", Name: "crawld", Owner: "DevMine", VCS: "git"},
+		},
+		{
+			"https://hg.example.org/devmine/srcanlzr, srcanlzr, Go, devmine",
+			&seedEntry{CloneURL: "https://hg.example.org/devmine/srcanlzr", Name: "srcanlzr", Language: "Go", Owner: "devmine", VCS: "hg"},
+		},
+		{
+			`{"url": "svn://svn.example.org/repos/project", "language": "C", "vcs": "SVN"}`,
+			&seedEntry{CloneURL: "svn://svn.example.org/repos/project", URL: "svn://svn.example.org/repos/project", Name: "project", Language: "C", Owner: "repos", VCS: "svn"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSeedLine(tt.line)
+		if err != nil {
+			t.Errorf("parseSeedLine(%q): unexpected error: %v", tt.line, err)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("parseSeedLine(%q): expected %+v, found %+v", tt.line, tt.want, got)
+		}
+	}
+}
+
+func TestParseSeedLineInvalid(t *testing.T) {
+	for _, line := range []string{"not a url", `{"name": "crawld"}`, "https://github.com/"} {
+		if _, err := parseSeedLine(line); err == nil {
+			t.Errorf("parseSeedLine(%q): expected an error", line)
+		}
+	}
+}
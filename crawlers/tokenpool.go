@@ -0,0 +1,207 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// coreResource is the GitHub API rate limit resource of most API calls.
+	coreResource = "core"
+
+	// searchResource is the GitHub API rate limit resource of the search API.
+	searchResource = "search"
+
+	// graphqlResource is the GitHub API rate limit resource of the GraphQL
+	// API, whose rate limit is expressed in points rather than in calls.
+	graphqlResource = "graphql"
+)
+
+// tokenPool is an http.RoundTripper that authenticates each request to the
+// GitHub API with the token of the pool that has the most API calls left.
+// Rate limits are tracked per token and per resource, so that the search API,
+// the core API and the GraphQL API are accounted separately.
+// An empty pool sends unauthenticated requests.
+type tokenPool struct {
+	mu     sync.Mutex
+	tokens []*poolToken
+
+	// costs are the expected costs of the next requests to each resource,
+	// when they cost more than 1.
+	costs map[string]int
+
+	// transport is used to actually perform the requests.
+	transport http.RoundTripper
+}
+
+// poolToken is an API token with its rate limits.
+type poolToken struct {
+	value  string
+	limits map[string]*rateLimit
+}
+
+// rateLimit is the rate limit of a resource for a token.
+type rateLimit struct {
+	remaining int
+	reset     time.Time
+}
+
+// newTokenPool creates a new token pool. Empty and duplicated tokens are
+// ignored.
+func newTokenPool(tokens []string) *tokenPool {
+	pool := &tokenPool{transport: http.DefaultTransport, costs: map[string]int{}}
+
+	seen := map[string]bool{}
+	for _, t := range tokens {
+		t = strings.Trim(t, " ")
+		if len(t) == 0 || seen[t] {
+			continue
+		}
+		seen[t] = true
+		pool.tokens = append(pool.tokens, &poolToken{value: t, limits: map[string]*rateLimit{}})
+	}
+
+	// unauthenticated requests have their own rate limit
+	if len(pool.tokens) == 0 {
+		pool.tokens = append(pool.tokens, &poolToken{limits: map[string]*rateLimit{}})
+	}
+
+	return pool
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (p *tokenPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource := requestResource(req)
+	token := p.pick(resource)
+
+	// a RoundTripper must not modify the given request
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r.Header[k] = append([]string(nil), v...)
+	}
+	if len(token.value) != 0 {
+		r.Header.Set("Authorization", "token "+token.value)
+	}
+
+	resp, err := p.transport.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p.update(token, resource, resp.Header)
+
+	return resp, nil
+}
+
+// pick returns the token with the most API calls left for the given
+// resource. Tokens whose rate limit is unknown or has been reset are
+// preferred.
+func (p *tokenPool) pick(resource string) *poolToken {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var best *poolToken
+	bestRemaining := -1
+	for _, t := range p.tokens {
+		limit, ok := t.limits[resource]
+		if !ok || now.After(limit.reset) {
+			return t
+		}
+		if limit.remaining > bestRemaining {
+			best = t
+			bestRemaining = limit.remaining
+		}
+	}
+
+	return best
+}
+
+// update records the rate limit of a token from the headers of a response.
+func (p *tokenPool) update(token *poolToken, resource string, header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	token.limits[resource] = &rateLimit{remaining: remaining, reset: time.Unix(reset, 0)}
+}
+
+// setCost records the expected cost of the next requests to the given
+// resource. This is typically the cost, in points, of the last GraphQL query.
+func (p *tokenPool) setCost(resource string, cost int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.costs[resource] = cost
+}
+
+// available tells whether at least one token of the pool has enough API calls
+// left for the next request to the given resource.
+func (p *tokenPool) available(resource string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cost := 1
+	if c, ok := p.costs[resource]; ok && c > cost {
+		cost = c
+	}
+
+	now := time.Now()
+	for _, t := range p.tokens {
+		limit, ok := t.limits[resource]
+		if !ok || limit.remaining >= cost || now.After(limit.reset) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nextReset returns the earliest time at which the rate limit of a token of
+// the pool is reset for the given resource.
+func (p *tokenPool) nextReset(resource string) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var next time.Time
+	for _, t := range p.tokens {
+		limit, ok := t.limits[resource]
+		if !ok {
+			return time.Now()
+		}
+		if next.IsZero() || limit.reset.Before(next) {
+			next = limit.reset
+		}
+	}
+
+	return next
+}
+
+// requestResource returns the rate limit resource a request to the GitHub API
+// is accounted to.
+func requestResource(req *http.Request) string {
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/search/"):
+		return searchResource
+	case req.URL.Path == "/graphql":
+		return graphqlResource
+	}
+	return coreResource
+}
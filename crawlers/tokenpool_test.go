@@ -0,0 +1,84 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTokenPool(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	remaining := map[string]int{"token exhausted": 0, "token plenty": 4242}
+
+	var used []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		used = append(used, auth)
+		w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(remaining[auth]))
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset))
+	}))
+	defer ts.Close()
+
+	pool := newTokenPool([]string{"exhausted", "", "plenty", "exhausted"})
+	if len(pool.tokens) != 2 {
+		t.Fatalf("len(pool.tokens): expected 2, found %d", len(pool.tokens))
+	}
+
+	client := &http.Client{Transport: pool}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(ts.URL + "/repositories")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	expected := []string{"token exhausted", "token plenty", "token plenty"}
+	if fmt.Sprint(used) != fmt.Sprint(expected) {
+		t.Errorf("tokens used: expected %v, found %v", expected, used)
+	}
+
+	if !pool.available(coreResource) {
+		t.Error("available(core): expected 'true', found 'false'")
+	}
+
+	// GraphQL queries may cost more than the points left
+	for i := 0; i < 2; i++ {
+		resp, err := client.Post(ts.URL+"/graphql", "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if !pool.available(graphqlResource) {
+		t.Error("available(graphql): expected 'true', found 'false'")
+	}
+	pool.setCost(graphqlResource, 5000)
+	if pool.available(graphqlResource) {
+		t.Error("available(graphql) with cost 5000: expected 'false', found 'true'")
+	}
+
+	// rate limits of the search API are tracked separately
+	remaining["token plenty"] = 0
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(ts.URL + "/search/repositories")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if pool.available(searchResource) {
+		t.Error("available(search): expected 'false', found 'true'")
+	}
+	if next := pool.nextReset(searchResource); next.Unix() != reset {
+		t.Errorf("nextReset(search): expected %d, found %d", reset, next.Unix())
+	}
+}
@@ -6,6 +6,7 @@ package crawlers
 
 import (
 	"bytes"
+	"database/sql"
 	"errors"
 	"fmt"
 	"strings"
@@ -70,6 +71,26 @@ func formatTimestamp(timeStamp *github.Timestamp) string {
 	return timeStamp.Format(timeFormat)
 }
 
+// formatNullTimestamp is like formatTimestamp, but it returns nil, ie NULL,
+// if timeStamp is nil.
+func formatNullTimestamp(timeStamp *github.Timestamp) *string {
+	if timeStamp == nil {
+		return nil
+	}
+	str := formatTimestamp(timeStamp)
+	return &str
+}
+
+// formatTime formats a time.Time to a string suitable to use as a timestamp
+// with timezone PostgreSQL data type.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		glog.Error("'t' arg given is nil")
+		return time.Time{}.Format(time.RFC3339)
+	}
+	return t.Format(time.RFC3339)
+}
+
 // isLanguageWanted checks if language(s) is in the list of wanted
 // languages.
 func isLanguageWanted(suppLangs []string, prjLangs interface{}) (bool, error) {
@@ -87,6 +108,15 @@ func isLanguageWanted(suppLangs []string, prjLangs interface{}) (bool, error) {
 				}
 			}
 		}
+	case map[string]float64:
+		langs := prjLangs.(map[string]float64)
+		for k := range langs {
+			for _, v := range suppLangs {
+				if strings.EqualFold(k, v) {
+					return true, nil
+				}
+			}
+		}
 	case *string:
 		lang := prjLangs.(*string)
 		if lang == nil {
@@ -149,3 +179,159 @@ func verifyRepo(repo *github.Repository) error {
 
 	return nil
 }
+
+// isUserLinkedToRepo checks whether a user is already linked to the given
+// repository.
+func isUserLinkedToRepo(db *sql.DB, userID, repoID int64) bool {
+	row := db.QueryRow(
+		`SELECT COUNT(*) AS total
+		 FROM users_repositories
+		 WHERE user_id = $1 AND repository_id = $2`, userID, repoID)
+
+	var total int64
+	if err := row.Scan(&total); err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return total > 0
+}
+
+// linkUserToRepo creates a many to many relationship between a user and a
+// repository.
+func linkUserToRepo(db *sql.DB, userID, repoID int64) bool {
+	if isUserLinkedToRepo(db, userID, repoID) {
+		return true
+	}
+
+	fields := []string{"user_id", "repository_id"}
+
+	query := genInsQuery("users_repositories", fields...)
+
+	_, err := db.Exec(query, userID, repoID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// setUserRepoContributions sets the number of contributions of a user to a
+// repository. The user must already be linked to the repository.
+func setUserRepoContributions(db *sql.DB, userID, repoID int64, contributions int) bool {
+	_, err := db.Exec(
+		`UPDATE users_repositories
+		 SET contributions = $1
+		 WHERE user_id = $2 AND repository_id = $3`, contributions, userID, repoID)
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// setRepoLanguages replaces the languages breakdown of a repository, ie the
+// number of bytes of code written in each language, by langs.
+func setRepoLanguages(db *sql.DB, repoID int64, langs map[string]int) bool {
+	tx, err := db.Begin()
+	if err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	if _, err := tx.Exec("DELETE FROM repository_languages WHERE repository_id=$1", repoID); err != nil {
+		glog.Error(err)
+		tx.Rollback()
+		return false
+	}
+
+	query := genInsQuery("repository_languages", "repository_id", "language", "bytes")
+	for lang, bytes := range langs {
+		if _, err := tx.Exec(query, repoID, lang, bytes); err != nil {
+			glog.Error(err)
+			tx.Rollback()
+			return false
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// getRepoIDByCloneURL returns the id of the repository identified by
+// cloneURL in the repositories table.
+// If the repository is not in the table, then 0 is returned. If an error
+// occurs, -1 is returned.
+func getRepoIDByCloneURL(db *sql.DB, cloneURL *string) int {
+	if cloneURL == nil {
+		glog.Error("'cloneURL' arg given is nil")
+		return -1
+	}
+
+	var id int
+	err := db.QueryRow("SELECT id FROM repositories WHERE clone_url=$1", *cloneURL).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0
+	case err != nil:
+		glog.Error(err)
+		return -1
+	}
+	return id
+}
+
+// insertStatsSnapshot appends a snapshot of the metrics of an entity, given
+// by fields and values, to a stats table, unless they have not changed since
+// the previous snapshot. The entity is identified by keyField and key.
+func insertStatsSnapshot(db *sql.DB, table, keyField string, key int64, fields []string, values []*int) bool {
+	last := make([]sql.NullInt64, len(fields))
+	dest := make([]interface{}, len(fields))
+	for i := range last {
+		dest[i] = &last[i]
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s=$1 ORDER BY observed_at DESC LIMIT 1",
+		strings.Join(fields, ","), table, keyField)
+	err := db.QueryRow(query, key).Scan(dest...)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		glog.Error(err)
+		return false
+	default:
+		if !statsChanged(last, values) {
+			return true
+		}
+	}
+
+	now := time.Now()
+	args := []interface{}{key, formatTime(&now)}
+	for _, v := range values {
+		args = append(args, v)
+	}
+
+	query = genInsQuery(table, append([]string{keyField, "observed_at"}, fields...)...)
+	if _, err := db.Exec(query, args...); err != nil {
+		glog.Error(err)
+		return false
+	}
+
+	return true
+}
+
+// statsChanged tells whether values differ from the last snapshot of the
+// same metrics. A metric that has become unknown, or known, has changed.
+func statsChanged(last []sql.NullInt64, values []*int) bool {
+	for i, v := range values {
+		if last[i].Valid != (v != nil) || (v != nil && last[i].Int64 != int64(*v)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package crawlers
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestStatsChanged(t *testing.T) {
+	ten, eleven := 10, 11
+
+	tests := []struct {
+		name    string
+		last    []sql.NullInt64
+		values  []*int
+		changed bool
+	}{
+		{"equal", []sql.NullInt64{{Int64: 10, Valid: true}, {}}, []*int{&ten, nil}, false},
+		{"changed", []sql.NullInt64{{Int64: 10, Valid: true}, {Int64: 10, Valid: true}}, []*int{&ten, &eleven}, true},
+		{"nil to value", []sql.NullInt64{{}}, []*int{&ten}, true},
+		{"value to nil", []sql.NullInt64{{Int64: 10, Valid: true}}, []*int{nil}, true},
+		// a NULL column scanned as 0 is not a count of 0
+		{"nil to zero", []sql.NullInt64{{}}, []*int{new(int)}, true},
+	}
+
+	for _, tt := range tests {
+		if changed := statsChanged(tt.last, tt.values); changed != tt.changed {
+			t.Errorf("%s: expected %v, found %v", tt.name, tt.changed, changed)
+		}
+	}
+}
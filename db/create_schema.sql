@@ -28,31 +28,41 @@ SET search_path = public, pg_catalog;
 SET default_with_oids = false;
 
 --
--- Name: gh_organizations; Type: TABLE; Schema: public; Owner: -
+-- Name: bb_repositories; Type: TABLE; Schema: public; Owner: -
 --
 
-CREATE TABLE gh_organizations (
+CREATE TABLE bb_repositories (
     id bigint NOT NULL,
-    github_id bigint NOT NULL,
-    login character varying NOT NULL,
-    avatar_url character varying,
+    repository_id bigint NOT NULL,
+    bitbucket_uuid character varying NOT NULL,
+    full_name character varying,
+    description character varying,
+    website character varying,
+    scm character varying,
+    fork boolean,
+    parent_full_name character varying,
+    main_branch character varying,
     html_url character varying,
-    name character varying,
-    company character varying,
-    blog character varying,
-    location character varying,
-    email character varying,
-    collaborators_count integer,
+    has_issues boolean,
+    has_wiki boolean,
+    size_in_bytes bigint,
     created_at timestamp with time zone,
     updated_at timestamp with time zone
 );
 
 
 --
--- Name: gh_organizations_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+-- Name: COLUMN bb_repositories.scm; Type: COMMENT; Schema: public; Owner: -
 --
 
-CREATE SEQUENCE gh_organizations_id_seq
+COMMENT ON COLUMN bb_repositories.scm IS 'Version control system of the repository, either git or hg.';
+
+
+--
+-- Name: bb_repositories_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE bb_repositories_id_seq
     START WITH 1
     INCREMENT BY 1
     NO MINVALUE
@@ -61,51 +71,34 @@ CREATE SEQUENCE gh_organizations_id_seq
 
 
 --
--- Name: gh_organizations_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+-- Name: bb_repositories_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
 --
 
-ALTER SEQUENCE gh_organizations_id_seq OWNED BY gh_organizations.id;
+ALTER SEQUENCE bb_repositories_id_seq OWNED BY bb_repositories.id;
 
 
 --
--- Name: gh_repositories; Type: TABLE; Schema: public; Owner: -
+-- Name: bb_users; Type: TABLE; Schema: public; Owner: -
 --
 
-CREATE TABLE gh_repositories (
+CREATE TABLE bb_users (
     id bigint NOT NULL,
-    repository_id bigint NOT NULL,
-    github_id bigint NOT NULL,
-    full_name character varying,
-    description character varying,
-    homepage character varying,
-    fork boolean,
-    default_branch character varying,
-    master_branch character varying,
+    user_id bigint NOT NULL,
+    bitbucket_uuid character varying NOT NULL,
+    account_id character varying,
+    nickname character varying,
+    display_name character varying,
     html_url character varying,
-    forks_count integer,
-    open_issues_count integer,
-    stargazers_count integer,
-    subscribers_count integer,
-    watchers_count integer,
-    size_in_kb integer,
-    created_at timestamp with time zone,
-    updated_at timestamp with time zone,
-    pushed_at timestamp with time zone
+    avatar_url character varying,
+    created_at timestamp with time zone
 );
 
 
 --
--- Name: COLUMN gh_repositories.size_in_kb; Type: COMMENT; Schema: public; Owner: -
---
-
-COMMENT ON COLUMN gh_repositories.size_in_kb IS 'Size of a bare git repository, in kilobytes.';
-
-
---
--- Name: gh_repositories_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+-- Name: bb_users_id_seq; Type: SEQUENCE; Schema: public; Owner: -
 --
 
-CREATE SEQUENCE gh_repositories_id_seq
+CREATE SEQUENCE bb_users_id_seq
     START WITH 1
     INCREMENT BY 1
     NO MINVALUE
@@ -114,42 +107,42 @@ CREATE SEQUENCE gh_repositories_id_seq
 
 
 --
--- Name: gh_repositories_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+-- Name: bb_users_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
 --
 
-ALTER SEQUENCE gh_repositories_id_seq OWNED BY gh_repositories.id;
+ALTER SEQUENCE bb_users_id_seq OWNED BY bb_users.id;
 
 
 --
--- Name: gh_users; Type: TABLE; Schema: public; Owner: -
+-- Name: bb_users_workspaces; Type: TABLE; Schema: public; Owner: -
 --
 
-CREATE TABLE gh_users (
+CREATE TABLE bb_users_workspaces (
+    bb_user_id bigint NOT NULL,
+    bb_workspace_id bigint NOT NULL
+);
+
+
+--
+-- Name: bb_workspaces; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE bb_workspaces (
     id bigint NOT NULL,
-    user_id bigint NOT NULL,
-    github_id bigint NOT NULL,
-    login character varying NOT NULL,
-    bio text,
-    blog character varying,
-    company character varying,
-    email character varying,
-    hireable boolean,
-    location character varying,
-    avatar_url character varying,
+    bitbucket_uuid character varying NOT NULL,
+    slug character varying NOT NULL,
+    name character varying,
     html_url character varying,
-    followers_count integer,
-    following_count integer,
-    collaborators_count integer,
-    created_at timestamp with time zone,
-    updated_at timestamp with time zone
+    avatar_url character varying,
+    created_at timestamp with time zone
 );
 
 
 --
--- Name: gh_users_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+-- Name: bb_workspaces_id_seq; Type: SEQUENCE; Schema: public; Owner: -
 --
 
-CREATE SEQUENCE gh_users_id_seq
+CREATE SEQUENCE bb_workspaces_id_seq
     START WITH 1
     INCREMENT BY 1
     NO MINVALUE
@@ -158,41 +151,36 @@ CREATE SEQUENCE gh_users_id_seq
 
 
 --
--- Name: gh_users_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+-- Name: bb_workspaces_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
 --
 
-ALTER SEQUENCE gh_users_id_seq OWNED BY gh_users.id;
+ALTER SEQUENCE bb_workspaces_id_seq OWNED BY bb_workspaces.id;
 
 
 --
--- Name: gh_users_organizations; Type: TABLE; Schema: public; Owner: -
+-- Name: crawler_cursors; Type: TABLE; Schema: public; Owner: -
 --
 
-CREATE TABLE gh_users_organizations (
-    gh_user_id bigint NOT NULL,
-    gh_organization_id bigint NOT NULL
+CREATE TABLE crawler_cursors (
+    id bigint NOT NULL,
+    crawler_name character varying NOT NULL,
+    since_id bigint NOT NULL,
+    updated_at timestamp with time zone
 );
 
 
 --
--- Name: repositories; Type: TABLE; Schema: public; Owner: -
+-- Name: COLUMN crawler_cursors.since_id; Type: COMMENT; Schema: public; Owner: -
 --
 
-CREATE TABLE repositories (
-    id bigint NOT NULL,
-    name character varying NOT NULL,
-    primary_language character varying NOT NULL,
-    clone_url character varying NOT NULL,
-    clone_path character varying NOT NULL,
-    vcs character varying NOT NULL
-);
+COMMENT ON COLUMN crawler_cursors.since_id IS 'ID, on the crawled platform, of the last repository processed by the crawler.';
 
 
 --
--- Name: repositories_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+-- Name: crawler_cursors_id_seq; Type: SEQUENCE; Schema: public; Owner: -
 --
 
-CREATE SEQUENCE repositories_id_seq
+CREATE SEQUENCE crawler_cursors_id_seq
     START WITH 1
     INCREMENT BY 1
     NO MINVALUE
@@ -201,29 +189,37 @@ CREATE SEQUENCE repositories_id_seq
 
 
 --
--- Name: repositories_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+-- Name: crawler_cursors_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
 --
 
-ALTER SEQUENCE repositories_id_seq OWNED BY repositories.id;
+ALTER SEQUENCE crawler_cursors_id_seq OWNED BY crawler_cursors.id;
 
 
 --
--- Name: users; Type: TABLE; Schema: public; Owner: -
+-- Name: gh_etags; Type: TABLE; Schema: public; Owner: -
 --
 
-CREATE TABLE users (
+CREATE TABLE gh_etags (
     id bigint NOT NULL,
-    username character varying NOT NULL,
-    name character varying,
-    email character varying
+    url character varying NOT NULL,
+    etag character varying,
+    last_modified character varying,
+    updated_at timestamp with time zone
 );
 
 
 --
--- Name: users_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+-- Name: COLUMN gh_etags.url; Type: COMMENT; Schema: public; Owner: -
 --
 
-CREATE SEQUENCE users_id_seq
+COMMENT ON COLUMN gh_etags.url IS 'URL of the resource, relative to the GitHub API URL.';
+
+
+--
+-- Name: gh_etags_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE gh_etags_id_seq
     START WITH 1
     INCREMENT BY 1
     NO MINVALUE
@@ -232,159 +228,1627 @@ CREATE SEQUENCE users_id_seq
 
 
 --
--- Name: users_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+-- Name: gh_etags_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
 --
 
-ALTER SEQUENCE users_id_seq OWNED BY users.id;
+ALTER SEQUENCE gh_etags_id_seq OWNED BY gh_etags.id;
 
 
 --
--- Name: users_repositories; Type: TABLE; Schema: public; Owner: -
+-- Name: gh_issue_labels; Type: TABLE; Schema: public; Owner: -
 --
 
-CREATE TABLE users_repositories (
-    user_id bigint NOT NULL,
-    repository_id bigint NOT NULL
+CREATE TABLE gh_issue_labels (
+    gh_issue_id bigint NOT NULL,
+    name character varying NOT NULL
 );
 
 
 --
--- Name: id; Type: DEFAULT; Schema: public; Owner: -
+-- Name: gh_issues; Type: TABLE; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY gh_organizations ALTER COLUMN id SET DEFAULT nextval('gh_organizations_id_seq'::regclass);
+CREATE TABLE gh_issues (
+    id bigint NOT NULL,
+    gh_repository_id bigint NOT NULL,
+    gh_user_id bigint,
+    github_id bigint,
+    number integer NOT NULL,
+    state character varying NOT NULL,
+    title character varying,
+    comments_count integer,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    closed_at timestamp with time zone
+);
 
 
 --
--- Name: id; Type: DEFAULT; Schema: public; Owner: -
+-- Name: COLUMN gh_issues.gh_user_id; Type: COMMENT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY gh_repositories ALTER COLUMN id SET DEFAULT nextval('gh_repositories_id_seq'::regclass);
+COMMENT ON COLUMN gh_issues.gh_user_id IS 'NULL when the account of the author has been deleted';
 
 
 --
--- Name: id; Type: DEFAULT; Schema: public; Owner: -
+-- Name: gh_issues_id_seq; Type: SEQUENCE; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY gh_users ALTER COLUMN id SET DEFAULT nextval('gh_users_id_seq'::regclass);
+CREATE SEQUENCE gh_issues_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
 
 
 --
--- Name: id; Type: DEFAULT; Schema: public; Owner: -
+-- Name: gh_issues_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY repositories ALTER COLUMN id SET DEFAULT nextval('repositories_id_seq'::regclass);
+ALTER SEQUENCE gh_issues_id_seq OWNED BY gh_issues.id;
 
 
 --
--- Name: id; Type: DEFAULT; Schema: public; Owner: -
+-- Name: gh_organizations; Type: TABLE; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY users ALTER COLUMN id SET DEFAULT nextval('users_id_seq'::regclass);
+CREATE TABLE gh_organizations (
+    id bigint NOT NULL,
+    github_id bigint NOT NULL,
+    login character varying NOT NULL,
+    avatar_url character varying,
+    html_url character varying,
+    name character varying,
+    company character varying,
+    blog character varying,
+    location character varying,
+    email character varying,
+    collaborators_count integer,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone
+);
 
 
 --
--- Name: gh_organizations_pk; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: gh_organizations_id_seq; Type: SEQUENCE; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY gh_organizations
-    ADD CONSTRAINT gh_organizations_pk PRIMARY KEY (id);
+CREATE SEQUENCE gh_organizations_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
 
 
 --
--- Name: gh_organizations_unique_login; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: gh_organizations_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY gh_organizations
-    ADD CONSTRAINT gh_organizations_unique_login UNIQUE (login);
+ALTER SEQUENCE gh_organizations_id_seq OWNED BY gh_organizations.id;
 
 
 --
--- Name: gh_repositories_pk; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: gh_pull_request_labels; Type: TABLE; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY gh_repositories
-    ADD CONSTRAINT gh_repositories_pk PRIMARY KEY (id);
+CREATE TABLE gh_pull_request_labels (
+    gh_pull_request_id bigint NOT NULL,
+    name character varying NOT NULL
+);
 
 
 --
--- Name: gh_users_pk; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: gh_pull_requests; Type: TABLE; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY gh_users
-    ADD CONSTRAINT gh_users_pk PRIMARY KEY (id);
+CREATE TABLE gh_pull_requests (
+    id bigint NOT NULL,
+    gh_repository_id bigint NOT NULL,
+    gh_user_id bigint,
+    github_id bigint,
+    number integer NOT NULL,
+    state character varying NOT NULL,
+    title character varying,
+    comments_count integer,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    closed_at timestamp with time zone,
+    merged_at timestamp with time zone
+);
 
 
 --
--- Name: gh_users_unique_login; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: COLUMN gh_pull_requests.gh_user_id; Type: COMMENT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY gh_users
-    ADD CONSTRAINT gh_users_unique_login UNIQUE (login);
+COMMENT ON COLUMN gh_pull_requests.gh_user_id IS 'NULL when the account of the author has been deleted';
 
 
 --
--- Name: repositories_pk; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: gh_pull_requests_id_seq; Type: SEQUENCE; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY repositories
-    ADD CONSTRAINT repositories_pk PRIMARY KEY (id);
+CREATE SEQUENCE gh_pull_requests_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
 
 
 --
--- Name: repositories_unique_clone_path; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: gh_pull_requests_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY repositories
-    ADD CONSTRAINT repositories_unique_clone_path UNIQUE (clone_path);
+ALTER SEQUENCE gh_pull_requests_id_seq OWNED BY gh_pull_requests.id;
 
 
 --
--- Name: repositories_unique_clone_url; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: gh_release_assets; Type: TABLE; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY repositories
-    ADD CONSTRAINT repositories_unique_clone_url UNIQUE (clone_url);
+CREATE TABLE gh_release_assets (
+    gh_release_id bigint NOT NULL,
+    github_id bigint NOT NULL,
+    name character varying,
+    content_type character varying,
+    size bigint,
+    download_count integer
+);
 
 
 --
--- Name: users_pk; Type: CONSTRAINT; Schema: public; Owner: -
+-- Name: gh_releases; Type: TABLE; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY users
-    ADD CONSTRAINT users_pk PRIMARY KEY (id);
+CREATE TABLE gh_releases (
+    id bigint NOT NULL,
+    gh_repository_id bigint NOT NULL,
+    gh_user_id bigint,
+    github_id bigint NOT NULL,
+    tag_name character varying NOT NULL,
+    name character varying,
+    draft boolean,
+    prerelease boolean,
+    created_at timestamp with time zone,
+    published_at timestamp with time zone
+);
 
 
 --
--- Name: gh_repositories_fk_repositories; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: COLUMN gh_releases.gh_user_id; Type: COMMENT; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY gh_repositories
-    ADD CONSTRAINT gh_repositories_fk_repositories FOREIGN KEY (repository_id) REFERENCES repositories(id);
+COMMENT ON COLUMN gh_releases.gh_user_id IS 'NULL when the account of the author has been deleted';
 
 
 --
--- Name: gh_users_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: gh_releases_id_seq; Type: SEQUENCE; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY gh_users
-    ADD CONSTRAINT gh_users_fk_users FOREIGN KEY (user_id) REFERENCES users(id);
+CREATE SEQUENCE gh_releases_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
 
 
 --
--- Name: gh_users_organizations_fk_organization; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: gh_releases_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY gh_users_organizations
-    ADD CONSTRAINT gh_users_organizations_fk_organization FOREIGN KEY (gh_organization_id) REFERENCES gh_organizations(id);
+ALTER SEQUENCE gh_releases_id_seq OWNED BY gh_releases.id;
 
 
 --
--- Name: gh_users_organizations_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+-- Name: gh_repositories; Type: TABLE; Schema: public; Owner: -
 --
 
-ALTER TABLE ONLY gh_users_organizations
-    ADD CONSTRAINT gh_users_organizations_fk_users FOREIGN KEY (gh_user_id) REFERENCES gh_users(id);
+CREATE TABLE gh_repositories (
+    id bigint NOT NULL,
+    repository_id bigint NOT NULL,
+    github_id bigint NOT NULL,
+    full_name character varying,
+    description character varying,
+    homepage character varying,
+    fork boolean,
+    default_branch character varying,
+    master_branch character varying,
+    html_url character varying,
+    forks_count integer,
+    open_issues_count integer,
+    stargazers_count integer,
+    subscribers_count integer,
+    watchers_count integer,
+    size_in_kb integer,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    pushed_at timestamp with time zone,
+    license character varying,
+    archived boolean,
+    disabled boolean,
+    is_template boolean,
+    visibility character varying,
+    parent_github_id bigint,
+    source_github_id bigint,
+    parent_id bigint,
+    source_id bigint
+);
+
+
+--
+-- Name: COLUMN gh_repositories.size_in_kb; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN gh_repositories.size_in_kb IS 'Size of a bare git repository, in kilobytes.';
+
+
+--
+-- Name: COLUMN gh_repositories.license; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN gh_repositories.license IS 'SPDX identifier of the license of the repository, NOASSERTION when GitHub cannot tell it.';
+
+
+--
+-- Name: COLUMN gh_repositories.parent_github_id; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN gh_repositories.parent_github_id IS 'GitHub ID of the repository a fork has been forked from.';
+
+
+--
+-- Name: COLUMN gh_repositories.source_github_id; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN gh_repositories.source_github_id IS 'GitHub ID of the root repository of the network of a fork.';
+
+
+--
+-- Name: COLUMN gh_repositories.parent_id; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN gh_repositories.parent_id IS 'Repository a fork has been forked from, NULL until it is crawled.';
+
+
+--
+-- Name: COLUMN gh_repositories.source_id; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN gh_repositories.source_id IS 'Root repository of the network of a fork, NULL until it is crawled.';
+
+
+--
+-- Name: gh_repositories_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE gh_repositories_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: gh_repositories_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE gh_repositories_id_seq OWNED BY gh_repositories.id;
+
+
+--
+-- Name: gh_repository_stats; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gh_repository_stats (
+    gh_repository_id bigint NOT NULL,
+    observed_at timestamp with time zone NOT NULL,
+    stargazers_count integer,
+    forks_count integer,
+    subscribers_count integer,
+    open_issues_count integer,
+    size integer
+);
+
+
+--
+-- Name: gh_repository_topics; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gh_repository_topics (
+    gh_repository_id bigint NOT NULL,
+    topic character varying NOT NULL
+);
+
+
+--
+-- Name: gh_stargazers; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gh_stargazers (
+    gh_repository_id bigint NOT NULL,
+    gh_user_id bigint NOT NULL,
+    starred_at timestamp with time zone
+);
+
+
+--
+-- Name: gh_tags; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gh_tags (
+    gh_repository_id bigint NOT NULL,
+    name character varying NOT NULL,
+    commit_sha character varying
+);
+
+
+--
+-- Name: gh_user_stats; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gh_user_stats (
+    gh_user_id bigint NOT NULL,
+    observed_at timestamp with time zone NOT NULL,
+    followers_count integer,
+    following_count integer,
+    public_repos_count integer,
+    public_gists_count integer
+);
+
+
+--
+-- Name: gh_users; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gh_users (
+    id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    github_id bigint NOT NULL,
+    login character varying NOT NULL,
+    bio text,
+    blog character varying,
+    company character varying,
+    email character varying,
+    hireable boolean,
+    location character varying,
+    avatar_url character varying,
+    html_url character varying,
+    followers_count integer,
+    following_count integer,
+    collaborators_count integer,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone
+);
+
+
+--
+-- Name: gh_users_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE gh_users_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: gh_users_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE gh_users_id_seq OWNED BY gh_users.id;
+
+
+--
+-- Name: gh_users_organizations; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gh_users_organizations (
+    gh_user_id bigint NOT NULL,
+    gh_organization_id bigint NOT NULL
+);
+
+
+--
+-- Name: gh_watchers; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gh_watchers (
+    gh_repository_id bigint NOT NULL,
+    gh_user_id bigint NOT NULL
+);
+
+
+--
+-- Name: gl_groups; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gl_groups (
+    id bigint NOT NULL,
+    gitlab_id bigint NOT NULL,
+    name character varying,
+    path character varying NOT NULL,
+    full_path character varying,
+    description character varying,
+    visibility character varying,
+    avatar_url character varying,
+    web_url character varying NOT NULL,
+    created_at timestamp with time zone
+);
+
+
+--
+-- Name: gl_groups_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE gl_groups_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: gl_groups_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE gl_groups_id_seq OWNED BY gl_groups.id;
+
+
+--
+-- Name: gl_projects; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gl_projects (
+    id bigint NOT NULL,
+    repository_id bigint NOT NULL,
+    gitlab_id bigint NOT NULL,
+    path_with_namespace character varying,
+    description character varying,
+    default_branch character varying,
+    web_url character varying,
+    fork boolean,
+    forks_count integer,
+    star_count integer,
+    open_issues_count integer,
+    created_at timestamp with time zone,
+    last_activity_at timestamp with time zone
+);
+
+
+--
+-- Name: gl_projects_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE gl_projects_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: gl_projects_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE gl_projects_id_seq OWNED BY gl_projects.id;
+
+
+--
+-- Name: gl_users; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gl_users (
+    id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    gitlab_id bigint NOT NULL,
+    username character varying NOT NULL,
+    state character varying,
+    bio text,
+    location character varying,
+    public_email character varying,
+    website_url character varying,
+    organization character varying,
+    avatar_url character varying,
+    web_url character varying NOT NULL,
+    created_at timestamp with time zone
+);
+
+
+--
+-- Name: gl_users_groups; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gl_users_groups (
+    gl_user_id bigint NOT NULL,
+    gl_group_id bigint NOT NULL
+);
+
+
+--
+-- Name: gl_users_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE gl_users_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: gl_users_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE gl_users_id_seq OWNED BY gl_users.id;
+
+
+--
+-- Name: gt_organizations; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gt_organizations (
+    id bigint NOT NULL,
+    instance_url character varying NOT NULL,
+    gitea_id bigint NOT NULL,
+    name character varying NOT NULL,
+    full_name character varying,
+    description character varying,
+    website character varying,
+    location character varying,
+    avatar_url character varying
+);
+
+
+--
+-- Name: COLUMN gt_organizations.instance_url; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN gt_organizations.instance_url IS 'URL of the Gitea, Gogs or Forgejo instance the organization belongs to.';
+
+
+--
+-- Name: gt_organizations_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE gt_organizations_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: gt_organizations_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE gt_organizations_id_seq OWNED BY gt_organizations.id;
+
+
+--
+-- Name: gt_repositories; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gt_repositories (
+    id bigint NOT NULL,
+    repository_id bigint NOT NULL,
+    gitea_id bigint NOT NULL,
+    full_name character varying,
+    description character varying,
+    website character varying,
+    fork boolean,
+    mirror boolean,
+    archived boolean,
+    html_url character varying,
+    default_branch character varying,
+    stars_count integer,
+    forks_count integer,
+    subscribers_count integer,
+    open_issues_count integer,
+    size_in_kb integer,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone
+);
+
+
+--
+-- Name: gt_repositories_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE gt_repositories_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: gt_repositories_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE gt_repositories_id_seq OWNED BY gt_repositories.id;
+
+
+--
+-- Name: gt_users; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gt_users (
+    id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    instance_url character varying NOT NULL,
+    gitea_id bigint NOT NULL,
+    login character varying NOT NULL,
+    full_name character varying,
+    email character varying,
+    avatar_url character varying,
+    location character varying,
+    website character varying,
+    description character varying,
+    followers_count integer,
+    following_count integer,
+    created_at timestamp with time zone
+);
+
+
+--
+-- Name: COLUMN gt_users.instance_url; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN gt_users.instance_url IS 'URL of the Gitea, Gogs or Forgejo instance the user belongs to.';
+
+
+--
+-- Name: gt_users_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE gt_users_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: gt_users_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE gt_users_id_seq OWNED BY gt_users.id;
+
+
+--
+-- Name: gt_users_organizations; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE gt_users_organizations (
+    gt_user_id bigint NOT NULL,
+    gt_organization_id bigint NOT NULL
+);
+
+
+--
+-- Name: repositories; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE repositories (
+    id bigint NOT NULL,
+    name character varying NOT NULL,
+    primary_language character varying NOT NULL,
+    clone_url character varying NOT NULL,
+    clone_path character varying NOT NULL,
+    vcs character varying NOT NULL,
+    gone_at timestamp with time zone,
+    previous_clone_path character varying
+);
+
+
+--
+-- Name: COLUMN repositories.gone_at; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN repositories.gone_at IS 'time at which the repository was found deleted or unavailable for legal reasons, NULL if it is not gone';
+
+
+--
+-- Name: COLUMN repositories.previous_clone_path; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN repositories.previous_clone_path IS 'clone path of the repository before it was renamed or transferred, until its clone is moved';
+
+
+--
+-- Name: repositories_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE repositories_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: repositories_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE repositories_id_seq OWNED BY repositories.id;
+
+
+--
+-- Name: repository_languages; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE repository_languages (
+    repository_id bigint NOT NULL,
+    language character varying NOT NULL,
+    bytes bigint NOT NULL
+);
+
+
+--
+-- Name: COLUMN repository_languages.bytes; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN repository_languages.bytes IS 'Number of bytes of code written in the language, as reported by the code hosting platform.';
+
+
+--
+-- Name: users; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE users (
+    id bigint NOT NULL,
+    username character varying NOT NULL,
+    name character varying,
+    email character varying
+);
+
+
+--
+-- Name: users_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE users_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: users_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE users_id_seq OWNED BY users.id;
+
+
+--
+-- Name: users_repositories; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE users_repositories (
+    user_id bigint NOT NULL,
+    repository_id bigint NOT NULL,
+    contributions integer
+);
+
+
+--
+-- Name: COLUMN users_repositories.contributions; Type: COMMENT; Schema: public; Owner: -
+--
+
+COMMENT ON COLUMN users_repositories.contributions IS 'Number of commits of the user to the repository, NULL when the user is not known to be a contributor.';
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_repositories ALTER COLUMN id SET DEFAULT nextval('bb_repositories_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_users ALTER COLUMN id SET DEFAULT nextval('bb_users_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_workspaces ALTER COLUMN id SET DEFAULT nextval('bb_workspaces_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY crawler_cursors ALTER COLUMN id SET DEFAULT nextval('crawler_cursors_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_etags ALTER COLUMN id SET DEFAULT nextval('gh_etags_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_issues ALTER COLUMN id SET DEFAULT nextval('gh_issues_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_organizations ALTER COLUMN id SET DEFAULT nextval('gh_organizations_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_pull_requests ALTER COLUMN id SET DEFAULT nextval('gh_pull_requests_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_releases ALTER COLUMN id SET DEFAULT nextval('gh_releases_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_repositories ALTER COLUMN id SET DEFAULT nextval('gh_repositories_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_users ALTER COLUMN id SET DEFAULT nextval('gh_users_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_groups ALTER COLUMN id SET DEFAULT nextval('gl_groups_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_projects ALTER COLUMN id SET DEFAULT nextval('gl_projects_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_users ALTER COLUMN id SET DEFAULT nextval('gl_users_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_organizations ALTER COLUMN id SET DEFAULT nextval('gt_organizations_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_repositories ALTER COLUMN id SET DEFAULT nextval('gt_repositories_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_users ALTER COLUMN id SET DEFAULT nextval('gt_users_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY repositories ALTER COLUMN id SET DEFAULT nextval('repositories_id_seq'::regclass);
+
+
+--
+-- Name: id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY users ALTER COLUMN id SET DEFAULT nextval('users_id_seq'::regclass);
+
+
+--
+-- Name: bb_repositories_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_repositories
+    ADD CONSTRAINT bb_repositories_pk PRIMARY KEY (id);
+
+
+--
+-- Name: bb_repositories_unique_bitbucket_uuid; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_repositories
+    ADD CONSTRAINT bb_repositories_unique_bitbucket_uuid UNIQUE (bitbucket_uuid);
+
+
+--
+-- Name: bb_users_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_users
+    ADD CONSTRAINT bb_users_pk PRIMARY KEY (id);
+
+
+--
+-- Name: bb_users_unique_bitbucket_uuid; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_users
+    ADD CONSTRAINT bb_users_unique_bitbucket_uuid UNIQUE (bitbucket_uuid);
+
+
+--
+-- Name: bb_workspaces_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_workspaces
+    ADD CONSTRAINT bb_workspaces_pk PRIMARY KEY (id);
+
+
+--
+-- Name: bb_workspaces_unique_bitbucket_uuid; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_workspaces
+    ADD CONSTRAINT bb_workspaces_unique_bitbucket_uuid UNIQUE (bitbucket_uuid);
+
+
+--
+-- Name: crawler_cursors_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY crawler_cursors
+    ADD CONSTRAINT crawler_cursors_pk PRIMARY KEY (id);
+
+
+--
+-- Name: crawler_cursors_unique_crawler_name; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY crawler_cursors
+    ADD CONSTRAINT crawler_cursors_unique_crawler_name UNIQUE (crawler_name);
+
+
+--
+-- Name: gh_etags_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_etags
+    ADD CONSTRAINT gh_etags_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gh_etags_unique_url; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_etags
+    ADD CONSTRAINT gh_etags_unique_url UNIQUE (url);
+
+
+--
+-- Name: gh_issue_labels_unique_gh_issue_id_name; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_issue_labels
+    ADD CONSTRAINT gh_issue_labels_unique_gh_issue_id_name UNIQUE (gh_issue_id, name);
+
+
+--
+-- Name: gh_issues_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_issues
+    ADD CONSTRAINT gh_issues_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gh_issues_unique_gh_repository_id_number; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_issues
+    ADD CONSTRAINT gh_issues_unique_gh_repository_id_number UNIQUE (gh_repository_id, number);
+
+
+--
+-- Name: gh_organizations_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_organizations
+    ADD CONSTRAINT gh_organizations_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gh_organizations_unique_login; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_organizations
+    ADD CONSTRAINT gh_organizations_unique_login UNIQUE (login);
+
+
+--
+-- Name: gh_pull_request_labels_unique_gh_pull_request_id_name; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_pull_request_labels
+    ADD CONSTRAINT gh_pull_request_labels_unique_gh_pull_request_id_name UNIQUE (gh_pull_request_id, name);
+
+
+--
+-- Name: gh_pull_requests_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_pull_requests
+    ADD CONSTRAINT gh_pull_requests_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gh_pull_requests_unique_gh_repository_id_number; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_pull_requests
+    ADD CONSTRAINT gh_pull_requests_unique_gh_repository_id_number UNIQUE (gh_repository_id, number);
+
+
+--
+-- Name: gh_release_assets_unique_github_id; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_release_assets
+    ADD CONSTRAINT gh_release_assets_unique_github_id UNIQUE (github_id);
+
+
+--
+-- Name: gh_releases_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_releases
+    ADD CONSTRAINT gh_releases_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gh_releases_unique_github_id; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_releases
+    ADD CONSTRAINT gh_releases_unique_github_id UNIQUE (github_id);
+
+
+--
+-- Name: gh_repositories_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_repositories
+    ADD CONSTRAINT gh_repositories_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gh_repository_stats_unique_gh_repository_id_observed_at; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_repository_stats
+    ADD CONSTRAINT gh_repository_stats_unique_gh_repository_id_observed_at UNIQUE (gh_repository_id, observed_at);
+
+
+--
+-- Name: gh_repository_topics_unique_gh_repository_id_topic; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_repository_topics
+    ADD CONSTRAINT gh_repository_topics_unique_gh_repository_id_topic UNIQUE (gh_repository_id, topic);
+
+
+--
+-- Name: gh_stargazers_unique_gh_repository_id_gh_user_id; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_stargazers
+    ADD CONSTRAINT gh_stargazers_unique_gh_repository_id_gh_user_id UNIQUE (gh_repository_id, gh_user_id);
+
+
+--
+-- Name: gh_tags_unique_gh_repository_id_name; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_tags
+    ADD CONSTRAINT gh_tags_unique_gh_repository_id_name UNIQUE (gh_repository_id, name);
+
+
+--
+-- Name: gh_user_stats_unique_gh_user_id_observed_at; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_user_stats
+    ADD CONSTRAINT gh_user_stats_unique_gh_user_id_observed_at UNIQUE (gh_user_id, observed_at);
+
+
+--
+-- Name: gh_users_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_users
+    ADD CONSTRAINT gh_users_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gh_users_unique_login; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_users
+    ADD CONSTRAINT gh_users_unique_login UNIQUE (login);
+
+
+--
+-- Name: gh_watchers_unique_gh_repository_id_gh_user_id; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_watchers
+    ADD CONSTRAINT gh_watchers_unique_gh_repository_id_gh_user_id UNIQUE (gh_repository_id, gh_user_id);
+
+
+--
+-- Name: gl_groups_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_groups
+    ADD CONSTRAINT gl_groups_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gl_groups_unique_web_url; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_groups
+    ADD CONSTRAINT gl_groups_unique_web_url UNIQUE (web_url);
+
+
+--
+-- Name: gl_projects_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_projects
+    ADD CONSTRAINT gl_projects_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gl_projects_unique_repository_id; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_projects
+    ADD CONSTRAINT gl_projects_unique_repository_id UNIQUE (repository_id);
+
+
+--
+-- Name: gl_users_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_users
+    ADD CONSTRAINT gl_users_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gl_users_unique_web_url; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_users
+    ADD CONSTRAINT gl_users_unique_web_url UNIQUE (web_url);
+
+
+--
+-- Name: gt_organizations_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_organizations
+    ADD CONSTRAINT gt_organizations_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gt_organizations_unique_instance_url_gitea_id; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_organizations
+    ADD CONSTRAINT gt_organizations_unique_instance_url_gitea_id UNIQUE (instance_url, gitea_id);
+
+
+--
+-- Name: gt_repositories_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_repositories
+    ADD CONSTRAINT gt_repositories_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gt_repositories_unique_repository_id; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_repositories
+    ADD CONSTRAINT gt_repositories_unique_repository_id UNIQUE (repository_id);
+
+
+--
+-- Name: gt_users_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_users
+    ADD CONSTRAINT gt_users_pk PRIMARY KEY (id);
+
+
+--
+-- Name: gt_users_unique_instance_url_gitea_id; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_users
+    ADD CONSTRAINT gt_users_unique_instance_url_gitea_id UNIQUE (instance_url, gitea_id);
+
+
+--
+-- Name: repositories_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY repositories
+    ADD CONSTRAINT repositories_pk PRIMARY KEY (id);
+
+
+--
+-- Name: repositories_unique_clone_path; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY repositories
+    ADD CONSTRAINT repositories_unique_clone_path UNIQUE (clone_path);
+
+
+--
+-- Name: repositories_unique_clone_url; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY repositories
+    ADD CONSTRAINT repositories_unique_clone_url UNIQUE (clone_url);
+
+
+--
+-- Name: repository_languages_unique_repository_id_language; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY repository_languages
+    ADD CONSTRAINT repository_languages_unique_repository_id_language UNIQUE (repository_id, language);
+
+
+--
+-- Name: users_pk; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY users
+    ADD CONSTRAINT users_pk PRIMARY KEY (id);
+
+
+--
+-- Name: bb_repositories_fk_repositories; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_repositories
+    ADD CONSTRAINT bb_repositories_fk_repositories FOREIGN KEY (repository_id) REFERENCES repositories(id);
+
+
+--
+-- Name: bb_users_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_users
+    ADD CONSTRAINT bb_users_fk_users FOREIGN KEY (user_id) REFERENCES users(id);
+
+
+--
+-- Name: bb_users_workspaces_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_users_workspaces
+    ADD CONSTRAINT bb_users_workspaces_fk_users FOREIGN KEY (bb_user_id) REFERENCES bb_users(id);
+
+
+--
+-- Name: bb_users_workspaces_fk_workspace; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY bb_users_workspaces
+    ADD CONSTRAINT bb_users_workspaces_fk_workspace FOREIGN KEY (bb_workspace_id) REFERENCES bb_workspaces(id);
+
+
+--
+-- Name: gh_issue_labels_fk_gh_issues; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_issue_labels
+    ADD CONSTRAINT gh_issue_labels_fk_gh_issues FOREIGN KEY (gh_issue_id) REFERENCES gh_issues(id);
+
+
+--
+-- Name: gh_issues_fk_repository; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_issues
+    ADD CONSTRAINT gh_issues_fk_repository FOREIGN KEY (gh_repository_id) REFERENCES gh_repositories(id);
+
+
+--
+-- Name: gh_issues_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_issues
+    ADD CONSTRAINT gh_issues_fk_users FOREIGN KEY (gh_user_id) REFERENCES gh_users(id);
+
+
+--
+-- Name: gh_pull_request_labels_fk_gh_pull_requests; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_pull_request_labels
+    ADD CONSTRAINT gh_pull_request_labels_fk_gh_pull_requests FOREIGN KEY (gh_pull_request_id) REFERENCES gh_pull_requests(id);
+
+
+--
+-- Name: gh_pull_requests_fk_repository; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_pull_requests
+    ADD CONSTRAINT gh_pull_requests_fk_repository FOREIGN KEY (gh_repository_id) REFERENCES gh_repositories(id);
+
+
+--
+-- Name: gh_pull_requests_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_pull_requests
+    ADD CONSTRAINT gh_pull_requests_fk_users FOREIGN KEY (gh_user_id) REFERENCES gh_users(id);
+
+
+--
+-- Name: gh_release_assets_fk_releases; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_release_assets
+    ADD CONSTRAINT gh_release_assets_fk_releases FOREIGN KEY (gh_release_id) REFERENCES gh_releases(id);
+
+
+--
+-- Name: gh_releases_fk_repository; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_releases
+    ADD CONSTRAINT gh_releases_fk_repository FOREIGN KEY (gh_repository_id) REFERENCES gh_repositories(id);
+
+
+--
+-- Name: gh_releases_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_releases
+    ADD CONSTRAINT gh_releases_fk_users FOREIGN KEY (gh_user_id) REFERENCES gh_users(id);
+
+
+--
+-- Name: gh_repositories_fk_parent; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_repositories
+    ADD CONSTRAINT gh_repositories_fk_parent FOREIGN KEY (parent_id) REFERENCES gh_repositories(id);
+
+
+--
+-- Name: gh_repositories_fk_repositories; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_repositories
+    ADD CONSTRAINT gh_repositories_fk_repositories FOREIGN KEY (repository_id) REFERENCES repositories(id);
+
+
+--
+-- Name: gh_repositories_fk_source; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_repositories
+    ADD CONSTRAINT gh_repositories_fk_source FOREIGN KEY (source_id) REFERENCES gh_repositories(id);
+
+
+--
+-- Name: gh_repository_stats_fk_repository; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_repository_stats
+    ADD CONSTRAINT gh_repository_stats_fk_repository FOREIGN KEY (gh_repository_id) REFERENCES gh_repositories(id);
+
+
+--
+-- Name: gh_repository_topics_fk_repository; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_repository_topics
+    ADD CONSTRAINT gh_repository_topics_fk_repository FOREIGN KEY (gh_repository_id) REFERENCES gh_repositories(id);
+
+
+--
+-- Name: gh_stargazers_fk_repository; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_stargazers
+    ADD CONSTRAINT gh_stargazers_fk_repository FOREIGN KEY (gh_repository_id) REFERENCES gh_repositories(id);
+
+
+--
+-- Name: gh_stargazers_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_stargazers
+    ADD CONSTRAINT gh_stargazers_fk_users FOREIGN KEY (gh_user_id) REFERENCES gh_users(id);
+
+
+--
+-- Name: gh_tags_fk_repository; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_tags
+    ADD CONSTRAINT gh_tags_fk_repository FOREIGN KEY (gh_repository_id) REFERENCES gh_repositories(id);
+
+
+--
+-- Name: gh_user_stats_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_user_stats
+    ADD CONSTRAINT gh_user_stats_fk_users FOREIGN KEY (gh_user_id) REFERENCES gh_users(id);
+
+
+--
+-- Name: gh_users_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_users
+    ADD CONSTRAINT gh_users_fk_users FOREIGN KEY (user_id) REFERENCES users(id);
+
+
+--
+-- Name: gh_users_organizations_fk_organization; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_users_organizations
+    ADD CONSTRAINT gh_users_organizations_fk_organization FOREIGN KEY (gh_organization_id) REFERENCES gh_organizations(id);
+
+
+--
+-- Name: gh_users_organizations_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_users_organizations
+    ADD CONSTRAINT gh_users_organizations_fk_users FOREIGN KEY (gh_user_id) REFERENCES gh_users(id);
+
+
+--
+-- Name: gh_watchers_fk_repository; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_watchers
+    ADD CONSTRAINT gh_watchers_fk_repository FOREIGN KEY (gh_repository_id) REFERENCES gh_repositories(id);
+
+
+--
+-- Name: gh_watchers_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gh_watchers
+    ADD CONSTRAINT gh_watchers_fk_users FOREIGN KEY (gh_user_id) REFERENCES gh_users(id);
+
+
+--
+-- Name: gl_projects_fk_repositories; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_projects
+    ADD CONSTRAINT gl_projects_fk_repositories FOREIGN KEY (repository_id) REFERENCES repositories(id);
+
+
+--
+-- Name: gl_users_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_users
+    ADD CONSTRAINT gl_users_fk_users FOREIGN KEY (user_id) REFERENCES users(id);
+
+
+--
+-- Name: gl_users_groups_fk_group; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_users_groups
+    ADD CONSTRAINT gl_users_groups_fk_group FOREIGN KEY (gl_group_id) REFERENCES gl_groups(id);
+
+
+--
+-- Name: gl_users_groups_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gl_users_groups
+    ADD CONSTRAINT gl_users_groups_fk_users FOREIGN KEY (gl_user_id) REFERENCES gl_users(id);
+
+
+--
+-- Name: gt_repositories_fk_repositories; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_repositories
+    ADD CONSTRAINT gt_repositories_fk_repositories FOREIGN KEY (repository_id) REFERENCES repositories(id);
+
+
+--
+-- Name: gt_users_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_users
+    ADD CONSTRAINT gt_users_fk_users FOREIGN KEY (user_id) REFERENCES users(id);
+
+
+--
+-- Name: gt_users_organizations_fk_organizations; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_users_organizations
+    ADD CONSTRAINT gt_users_organizations_fk_organizations FOREIGN KEY (gt_organization_id) REFERENCES gt_organizations(id);
+
+
+--
+-- Name: gt_users_organizations_fk_users; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY gt_users_organizations
+    ADD CONSTRAINT gt_users_organizations_fk_users FOREIGN KEY (gt_user_id) REFERENCES gt_users(id);
+
+
+--
+-- Name: repository_languages_fk_repository; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY repository_languages
+    ADD CONSTRAINT repository_languages_fk_repository FOREIGN KEY (repository_id) REFERENCES repositories(id);
 
 
 --
@@ -0,0 +1,48 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// cmdWaitDelay is the time to wait for the output of a command to be closed
+// once it has been killed, since its children may keep it open.
+const cmdWaitDelay = 5 * time.Second
+
+// runCmd runs a VCS command in dir, or in the current directory if dir is
+// empty, with the given environment variables in addition to the ones of
+// crawld. The command is killed if it runs for longer than timeout, unless
+// timeout is 0.
+// Its failures are classified with cmdErrorToRepoError, from its standard
+// error, and ErrTimeout is returned if it has been killed.
+func runCmd(timeout time.Duration, dir string, env []string, name string, args ...string) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = cmdWaitDelay
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return ErrTimeout
+		}
+		return cmdErrorToRepoError(stderr.Bytes(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestRunCmd(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	if err := runCmd(time.Second, "", nil, "sh", "-c", "exit 0"); err != nil {
+		t.Errorf("runCmd: expected no error, found %v", err)
+	}
+
+	if err := runCmd(100*time.Millisecond, "", nil, "sh", "-c", "exec sleep 10"); err != ErrTimeout {
+		t.Errorf("runCmd: expected ErrTimeout, found %v", err)
+	}
+
+	// the error is built from the standard error only
+	err := runCmd(0, "", []string{"MSG=fatal: bad object"}, "sh", "-c", `echo progress; echo "$MSG" >&2; exit 1`)
+	if err == nil || err.Error() != "fatal: bad object" {
+		t.Errorf("runCmd: expected 'fatal: bad object', found %v", err)
+	}
+
+	if err := runCmd(0, "", nil, "sh", "-c", "echo 'No space left on device' >&2; exit 1"); err != ErrNoSpace {
+		t.Errorf("runCmd: expected ErrNoSpace, found %v", err)
+	}
+}
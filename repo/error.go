@@ -6,8 +6,7 @@ package repo
 
 import (
 	"errors"
-
-	g2g "github.com/libgit2/git2go"
+	"strings"
 )
 
 var (
@@ -16,15 +15,45 @@ var (
 
 	// ErrNoSpace represents a space storage error.
 	ErrNoSpace = errors.New("no space left on device")
+
+	// ErrTimeout represents a VCS command that has been killed because it
+	// took too long.
+	ErrTimeout = errors.New("timeout")
 )
 
-// g2gErrorToRepoError returns a repo error when given a git2go error if it
-// it finds a corresponding match or simply the given error otherwise.
-// TODO when git2go adds support for ENOSPC type of error, update this method
-// accordingly to return ErrNoSpace.
-func g2gErrorToRepoError(err error) error {
-	if g2g.IsErrorClass(err, g2g.ErrClassNet) {
-		return ErrNetwork
+// networkErrorMessages are fragments of the error messages of the VCS
+// commands that denote a network error.
+var networkErrorMessages = []string{
+	"could not resolve host",
+	"name or service not known",
+	"temporary failure in name resolution",
+	"connection refused",
+	"connection timed out",
+	"network is unreachable",
+	"no route to host",
+	"connection reset by peer",
+	"failed to connect to",
+	"the remote end hung up unexpectedly",
+}
+
+// cmdErrorToRepoError returns a repo error when given the output and the
+// error of a VCS command if it finds a corresponding match or an error
+// holding the output of the command otherwise.
+func cmdErrorToRepoError(output []byte, err error) error {
+	msg := strings.ToLower(string(output))
+
+	if strings.Contains(msg, "no space left on device") {
+		return ErrNoSpace
+	}
+
+	for _, m := range networkErrorMessages {
+		if strings.Contains(msg, m) {
+			return ErrNetwork
+		}
+	}
+
+	if out := strings.TrimSpace(string(output)); out != "" {
+		return errors.New(out)
 	}
 	return err
 }
@@ -0,0 +1,36 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCmdErrorToRepoError(t *testing.T) {
+	exitErr := errors.New("exit status 255")
+
+	tests := []struct {
+		output   string
+		expected error
+	}{
+		{"abort: error: Name or service not known", ErrNetwork},
+		{"fatal: unable to access 'https://example.com/': Could not resolve host: example.com", ErrNetwork},
+		{"svn: E000111: Connection refused", ErrNetwork},
+		{"abort: No space left on device", ErrNoSpace},
+		{"", exitErr},
+	}
+
+	for _, tt := range tests {
+		if err := cmdErrorToRepoError([]byte(tt.output), exitErr); err != tt.expected {
+			t.Errorf("cmdErrorToRepoError(%q): expected %v, found %v", tt.output, tt.expected, err)
+		}
+	}
+
+	output := "abort: repository /tmp/missing not found"
+	if err := cmdErrorToRepoError([]byte(output+"\n"), exitErr); err == nil || err.Error() != output {
+		t.Errorf("cmdErrorToRepoError(%q): expected the output as error, found %v", output, err)
+	}
+}
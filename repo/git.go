@@ -2,6 +2,9 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+//go:build !nolibgit2
+// +build !nolibgit2
+
 package repo
 
 import (
@@ -10,6 +13,9 @@ import (
 	g2g "github.com/libgit2/git2go"
 )
 
+// defaultGitBackend is the git backend in use when none is configured.
+const defaultGitBackend = GitBackendLibgit2
+
 // gitRepo implements the Repo interface.
 type gitRepo struct {
 	absPath string
@@ -109,3 +115,14 @@ func (gr gitRepo) Cleanup() error {
 	}
 	return nil
 }
+
+// g2gErrorToRepoError returns a repo error when given a git2go error if it
+// it finds a corresponding match or simply the given error otherwise.
+// TODO when git2go adds support for ENOSPC type of error, update this method
+// accordingly to return ErrNoSpace.
+func g2gErrorToRepoError(err error) error {
+	if g2g.IsErrorClass(err, g2g.ErrClassNet) {
+		return ErrNetwork
+	}
+	return err
+}
@@ -0,0 +1,19 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build nolibgit2
+// +build nolibgit2
+
+package repo
+
+import "errors"
+
+// defaultGitBackend is the git backend in use when none is configured.
+const defaultGitBackend = GitBackendGoGit
+
+// newGitRepo fails since the libgit2 git backend is not available when
+// building with the nolibgit2 tag.
+func newGitRepo(absPath string, url string) (Repo, error) {
+	return nil, errors.New("the libgit2 git backend is not available: crawld was built with the nolibgit2 tag")
+}
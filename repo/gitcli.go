@@ -0,0 +1,133 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// gitCLIRepo implements the Repo interface.
+type gitCLIRepo struct {
+	absPath string
+	url     string
+
+	// timeout is the maximum duration of the git commands, 0 for no limit.
+	timeout time.Duration
+
+	// depth is the number of commits to fetch, 0 for the whole history.
+	depth int
+
+	// singleBranch tells whether only the default branch is fetched.
+	singleBranch bool
+
+	// filter is the partial clone filter, if any.
+	filter string
+}
+
+// newGitCLIRepo creates a new gitCLIRepo. gitCLIRepo implements the Repo
+// interface for a git repository, using the git command. Hence, the
+// configuration of git, such as its credential helpers, applies.
+func newGitCLIRepo(absPath string, url string, opts Options) (*gitCLIRepo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, err
+	}
+
+	gr := &gitCLIRepo{
+		absPath:      absPath,
+		url:          url,
+		timeout:      opts.Timeout,
+		depth:        opts.CloneDepth,
+		singleBranch: opts.SingleBranch,
+		filter:       opts.CloneFilter,
+	}
+	return gr, nil
+}
+
+// AbsPath implements the AbsPath() method of the Repo interface.
+func (gr gitCLIRepo) AbsPath() string {
+	return gr.absPath
+}
+
+// SetAbsPath implements the SetAbsPath() method of the Repo interface.
+func (gr *gitCLIRepo) SetAbsPath(path string) {
+	gr.absPath = path
+}
+
+// URL implements the URL() method of the Repo interface.
+func (gr gitCLIRepo) URL() string {
+	return gr.url
+}
+
+// Clone implements the Clone() method of the Repo interface.
+// The partial clone is removed if cloning fails.
+func (gr gitCLIRepo) Clone() error {
+	if err := os.MkdirAll(filepath.Dir(gr.absPath), 0755); err != nil {
+		return err
+	}
+
+	args := []string{"clone", "--quiet"}
+	if gr.depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(gr.depth))
+	}
+	// --depth implies --single-branch
+	if gr.singleBranch {
+		args = append(args, "--single-branch")
+	} else if gr.depth > 0 {
+		args = append(args, "--no-single-branch")
+	}
+	if gr.filter != "" {
+		args = append(args, "--filter="+gr.filter)
+	}
+	args = append(args, "--", gr.url, gr.absPath)
+
+	if err := gr.run("", args...); err != nil {
+		os.RemoveAll(gr.absPath)
+		return err
+	}
+
+	return nil
+}
+
+// Update implements the Update() method of the Repo interface.
+// It fetches changes from remote and performs a fast-forward on the local
+// branch so as to match the remote branch.
+func (gr gitCLIRepo) Update() error {
+	// the refspec of single branch clones and the filter of partial clones
+	// are recorded in the configuration of the repository, unlike the depth
+	// of shallow clones
+	args := []string{"fetch", "--quiet"}
+	if gr.depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(gr.depth))
+	}
+	args = append(args, "origin")
+
+	if err := gr.run(gr.absPath, args...); err != nil {
+		return err
+	}
+
+	// fails in a detached HEAD state
+	if err := gr.run(gr.absPath, "symbolic-ref", "--quiet", "HEAD"); err != nil {
+		return err
+	}
+
+	// a hard reset moves the local branch to the upstream branch and checks
+	// it out, discarding local changes
+	return gr.run(gr.absPath, "reset", "--quiet", "--hard", "@{upstream}")
+}
+
+// Cleanup implements the Cleanup() method of the Repo interface.
+func (gr gitCLIRepo) Cleanup() error {
+	return nil
+}
+
+// run runs the git command in dir with the given arguments, without ever
+// prompting for credentials and with untranslated messages.
+func (gr gitCLIRepo) run(dir string, args ...string) error {
+	return runCmd(gr.timeout, dir, []string{"GIT_TERMINAL_PROMPT=0", "LC_ALL=C"}, "git", args...)
+}
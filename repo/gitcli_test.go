@@ -0,0 +1,169 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitCLIRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "crawld-git-cli-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	src := filepath.Join(tmpDir, "src")
+	runGit(t, tmpDir, "init", "-q", src)
+	if err := ioutil.WriteFile(filepath.Join(src, "README"), []byte("crawld"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, src, "add", "README")
+	runGit(t, src, "commit", "-q", "-m", "first commit")
+
+	r, err := NewWithOptions("git", filepath.Join(tmpDir, "clones", "src"), src, Options{GitBackend: GitBackendCLI})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Cleanup()
+
+	if err := r.Clone(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(r.AbsPath(), "README")); err != nil {
+		t.Errorf("Clone: README not checked out: %v", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(src, "LICENSE"), []byte("BSD"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, src, "add", "LICENSE")
+	runGit(t, src, "commit", "-q", "-m", "second commit")
+
+	// local changes are discarded
+	if err := ioutil.WriteFile(filepath.Join(r.AbsPath(), "README"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(r.AbsPath(), "LICENSE")); err != nil {
+		t.Errorf("Update: LICENSE not checked out: %v", err)
+	}
+	if bs, _ := ioutil.ReadFile(filepath.Join(r.AbsPath(), "README")); string(bs) != "crawld" {
+		t.Errorf("Update: expected README to be reset, found %q", bs)
+	}
+
+	// a detached HEAD cannot be updated
+	runGit(t, r.AbsPath(), "checkout", "-q", "--detach")
+	if err := r.Update(); err == nil {
+		t.Error("Update: expected an error in a detached HEAD state")
+	}
+}
+
+func TestGitCLIRepoShallowPartialClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "crawld-git-cli-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	src := filepath.Join(tmpDir, "src")
+	runGit(t, tmpDir, "init", "-q", src)
+	runGit(t, src, "config", "uploadpack.allowFilter", "true")
+	commit := func(name string) {
+		if err := ioutil.WriteFile(filepath.Join(src, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, src, "add", name)
+		runGit(t, src, "commit", "-q", "-m", "add "+name)
+	}
+	commit("README")
+	commit("LICENSE")
+	runGit(t, src, "branch", "dev")
+
+	// local clones ignore --depth and --filter, unlike file:// ones
+	opts := Options{GitBackend: GitBackendCLI, CloneDepth: 1, SingleBranch: true, CloneFilter: "blob:none"}
+	r, err := NewWithOptions("git", filepath.Join(tmpDir, "clones", "src"), "file://"+src, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Cleanup()
+
+	if err := r.Clone(); err != nil {
+		t.Fatal(err)
+	}
+
+	gitOutput := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = r.AbsPath()
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %s: %v", strings.Join(args, " "), err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	if n := gitOutput("rev-list", "--count", "HEAD"); n != "1" {
+		t.Errorf("Clone: expected 1 commit, found %s", n)
+	}
+	if branches := gitOutput("branch", "-r"); strings.Contains(branches, "dev") {
+		t.Errorf("Clone: expected a single branch, found %q", branches)
+	}
+	if promisor := gitOutput("config", "remote.origin.promisor"); promisor != "true" {
+		t.Errorf("Clone: expected a partial clone, found promisor %q", promisor)
+	}
+
+	commit("AUTHORS")
+
+	if err := r.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(r.AbsPath(), "AUTHORS")); err != nil {
+		t.Errorf("Update: AUTHORS not checked out: %v", err)
+	}
+	if n := gitOutput("rev-list", "--count", "HEAD"); n != "1" {
+		t.Errorf("Update: expected 1 commit, found %s", n)
+	}
+}
+
+func TestGitCLIRepoCloneNetworkError(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "crawld-git-cli-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// nothing listens on port 1
+	r, err := NewWithOptions("git", filepath.Join(tmpDir, "clone"), "http://127.0.0.1:1/crawld.git", Options{GitBackend: GitBackendCLI})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Clone(); err != ErrNetwork {
+		t.Errorf("Clone: expected ErrNetwork, found %v", err)
+	}
+	if _, err := os.Stat(r.AbsPath()); !os.IsNotExist(err) {
+		t.Error("Clone: expected the partial clone to be removed")
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// goGitRepo implements the Repo interface.
+type goGitRepo struct {
+	absPath string
+	url     string
+
+	// depth is the number of commits to fetch, 0 for the whole history.
+	depth int
+
+	// singleBranch tells whether only the default branch is fetched.
+	singleBranch bool
+}
+
+// newGoGitRepo creates a new goGitRepo. goGitRepo implements the Repo
+// interface for a git repository, using go-git, a pure Go git
+// implementation.
+func newGoGitRepo(absPath string, url string, depth int, singleBranch bool) (*goGitRepo, error) {
+	return &goGitRepo{absPath: absPath, url: url, depth: depth, singleBranch: singleBranch}, nil
+}
+
+// AbsPath implements the AbsPath() method of the Repo interface.
+func (gr goGitRepo) AbsPath() string {
+	return gr.absPath
+}
+
+// SetAbsPath implements the SetAbsPath() method of the Repo interface.
+func (gr *goGitRepo) SetAbsPath(path string) {
+	gr.absPath = path
+}
+
+// URL implements the URL() method of the Repo interface.
+func (gr goGitRepo) URL() string {
+	return gr.url
+}
+
+// Clone implements the Clone() method of the Repo interface.
+func (gr goGitRepo) Clone() error {
+	opts := &git.CloneOptions{
+		URL:          gr.url,
+		Depth:        gr.depth,
+		SingleBranch: gr.singleBranch,
+	}
+
+	_, err := git.PlainClone(gr.absPath, false, opts)
+	if err != nil {
+		return goGitErrorToRepoError(err)
+	}
+
+	return nil
+}
+
+// Update implements the Update() method of the Repo interface.
+// It fetches changes from remote and performs a fast-forward on the local
+// branch so as to match the remote branch.
+func (gr goGitRepo) Update() error {
+	r, err := git.PlainOpen(gr.absPath)
+	if err != nil {
+		return goGitErrorToRepoError(err)
+	}
+
+	// shallow clones are kept shallow
+	err = r.Fetch(&git.FetchOptions{RemoteName: "origin", Depth: gr.depth})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return goGitErrorToRepoError(err)
+	}
+
+	ref, err := r.Head()
+	if err != nil {
+		return goGitErrorToRepoError(err)
+	}
+
+	if !ref.Name().IsBranch() {
+		return errors.New("repository reference is not a branch (likely in a detached HEAD state)")
+	}
+
+	cfg, err := r.Config()
+	if err != nil {
+		return goGitErrorToRepoError(err)
+	}
+
+	// the default branch of a clone tracks the branch of the same name of
+	// origin, even though its upstream branch may not be configured
+	branch, ok := cfg.Branches[ref.Name().Short()]
+	if !ok || branch.Remote == "" || branch.Merge == "" {
+		branch = &config.Branch{Remote: "origin", Merge: ref.Name()}
+	}
+
+	upstreamName := plumbing.NewRemoteReferenceName(branch.Remote, branch.Merge.Short())
+	upstream, err := r.Reference(upstreamName, true)
+	if err != nil {
+		return goGitErrorToRepoError(err)
+	}
+
+	// a hard reset moves the local branch to the upstream branch and checks
+	// it out, discarding local changes
+	w, err := r.Worktree()
+	if err != nil {
+		return goGitErrorToRepoError(err)
+	}
+
+	err = w.Reset(&git.ResetOptions{Commit: upstream.Hash(), Mode: git.HardReset})
+	if err != nil {
+		return goGitErrorToRepoError(err)
+	}
+
+	return nil
+}
+
+// Cleanup implements the Cleanup() method of the Repo interface.
+func (gr goGitRepo) Cleanup() error {
+	return nil
+}
+
+// goGitErrorToRepoError returns a repo error when given a go-git error if it
+// finds a corresponding match or simply the given error otherwise.
+func goGitErrorToRepoError(err error) error {
+	if _, ok := err.(net.Error); ok {
+		return ErrNetwork
+	}
+
+	if pe, ok := err.(*os.PathError); ok && pe.Err == syscall.ENOSPC {
+		return ErrNoSpace
+	}
+
+	// go-git wraps some of the errors it encounters into its own
+	if repoErr := cmdErrorToRepoError([]byte(err.Error()), err); repoErr == ErrNetwork || repoErr == ErrNoSpace {
+		return repoErr
+	}
+
+	return err
+}
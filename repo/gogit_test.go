@@ -0,0 +1,100 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// runGit runs the git command in dir, failing the test if it does not succeed.
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=gopher", "-c", "user.email=gopher@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v (%s)", args, err, out)
+	}
+}
+
+func TestGoGitRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "crawld-go-git-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	src := filepath.Join(tmpDir, "src")
+	runGit(t, tmpDir, "init", "-q", src)
+	if err := ioutil.WriteFile(filepath.Join(src, "README"), []byte("crawld"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, src, "add", "README")
+	runGit(t, src, "commit", "-q", "-m", "first commit")
+
+	r, err := NewWithOptions("git", filepath.Join(tmpDir, "clones", "src"), src, Options{GitBackend: GitBackendGoGit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Cleanup()
+
+	if err := r.Clone(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(r.AbsPath(), "README")); err != nil {
+		t.Errorf("Clone: README not checked out: %v", err)
+	}
+
+	// nothing to fetch
+	if err := r.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(src, "LICENSE"), []byte("BSD"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, src, "add", "LICENSE")
+	runGit(t, src, "commit", "-q", "-m", "second commit")
+
+	// local changes are discarded
+	if err := ioutil.WriteFile(filepath.Join(r.AbsPath(), "README"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(r.AbsPath(), "LICENSE")); err != nil {
+		t.Errorf("Update: LICENSE not checked out: %v", err)
+	}
+	if bs, _ := ioutil.ReadFile(filepath.Join(r.AbsPath(), "README")); string(bs) != "crawld" {
+		t.Errorf("Update: expected README to be reset, found %q", bs)
+	}
+}
+
+func TestGoGitErrorToRepoError(t *testing.T) {
+	if err := goGitErrorToRepoError(&os.PathError{Op: "write", Path: "pack", Err: syscall.ENOSPC}); err != ErrNoSpace {
+		t.Errorf("goGitErrorToRepoError(ENOSPC): expected ErrNoSpace, found %v", err)
+	}
+
+	notFound := errors.New("repository not found")
+	if err := goGitErrorToRepoError(notFound); err != notFound {
+		t.Errorf("goGitErrorToRepoError(%v): expected the error itself, found %v", notFound, err)
+	}
+}
+
+func TestNewWithOptionsInvalidGitBackend(t *testing.T) {
+	if _, err := NewWithOptions("git", "/tmp/crawld", "https://example.com/crawld.git", Options{GitBackend: "jgit"}); err == nil {
+		t.Error("NewWithOptions: expected an error for an unsupported git backend")
+	}
+}
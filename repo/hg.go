@@ -0,0 +1,77 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// hgRepo implements the Repo interface.
+type hgRepo struct {
+	absPath string
+	url     string
+
+	// timeout is the maximum duration of the hg commands, 0 for no limit.
+	timeout time.Duration
+}
+
+// newHgRepo creates a new hgRepo. hgRepo implements the Repo interface for a
+// mercurial repository, using the hg command.
+func newHgRepo(absPath string, url string, timeout time.Duration) (*hgRepo, error) {
+	if _, err := exec.LookPath("hg"); err != nil {
+		return nil, err
+	}
+
+	return &hgRepo{absPath: absPath, url: url, timeout: timeout}, nil
+}
+
+// AbsPath implements the AbsPath() method of the Repo interface.
+func (hr hgRepo) AbsPath() string {
+	return hr.absPath
+}
+
+// SetAbsPath implements the SetAbsPath() method of the Repo interface.
+func (hr *hgRepo) SetAbsPath(path string) {
+	hr.absPath = path
+}
+
+// URL implements the URL() method of the Repo interface.
+func (hr hgRepo) URL() string {
+	return hr.url
+}
+
+// Clone implements the Clone() method of the Repo interface.
+func (hr hgRepo) Clone() error {
+	if err := os.MkdirAll(filepath.Dir(hr.absPath), 0755); err != nil {
+		return err
+	}
+
+	return hr.run("clone", "--", hr.url, hr.absPath)
+}
+
+// Update implements the Update() method of the Repo interface.
+// It pulls changes from the remote repository and updates the working
+// directory to the head of the default branch, discarding local changes.
+func (hr hgRepo) Update() error {
+	if err := hr.run("pull", "-R", hr.absPath, "--", hr.url); err != nil {
+		return err
+	}
+
+	return hr.run("update", "-R", hr.absPath, "--clean", "default")
+}
+
+// Cleanup implements the Cleanup() method of the Repo interface.
+func (hr hgRepo) Cleanup() error {
+	return nil
+}
+
+// run runs the hg command with the given arguments, non-interactively and
+// regardless of the configuration of the user.
+func (hr hgRepo) run(args ...string) error {
+	return runCmd(hr.timeout, "", []string{"HGPLAIN=1"}, "hg", append([]string{"--noninteractive"}, args...)...)
+}
@@ -0,0 +1,96 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// hg runs the hg command in dir, failing the test if it does not succeed.
+func hg(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("hg", append([]string{"--noninteractive", "--config", "ui.username=gopher"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "HGPLAIN=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("hg %v: %v (%s)", args, err, out)
+	}
+}
+
+func TestHgRepo(t *testing.T) {
+	if _, err := exec.LookPath("hg"); err != nil {
+		t.Skip("hg is not installed")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "crawld-hg-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	src := filepath.Join(tmpDir, "src")
+	hg(t, tmpDir, "init", src)
+	if err := ioutil.WriteFile(filepath.Join(src, "README"), []byte("crawld"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hg(t, src, "commit", "-A", "-m", "first commit")
+
+	r, err := New("hg", filepath.Join(tmpDir, "clones", "src"), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Cleanup()
+
+	if err := r.Clone(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(r.AbsPath(), "README")); err != nil {
+		t.Errorf("Clone: README not checked out: %v", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(src, "LICENSE"), []byte("BSD"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hg(t, src, "commit", "-A", "-m", "second commit")
+
+	// local changes are discarded
+	if err := ioutil.WriteFile(filepath.Join(r.AbsPath(), "README"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(r.AbsPath(), "LICENSE")); err != nil {
+		t.Errorf("Update: LICENSE not checked out: %v", err)
+	}
+	if bs, _ := ioutil.ReadFile(filepath.Join(r.AbsPath(), "README")); string(bs) != "crawld" {
+		t.Errorf("Update: expected README to be reset, found %q", bs)
+	}
+}
+
+func TestHgRepoCloneNotFound(t *testing.T) {
+	if _, err := exec.LookPath("hg"); err != nil {
+		t.Skip("hg is not installed")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "crawld-hg-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	r, err := New("hg", filepath.Join(tmpDir, "clone"), filepath.Join(tmpDir, "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Clone(); err == nil || err == ErrNetwork || err == ErrNoSpace {
+		t.Errorf("Clone: expected a plain error, found %v", err)
+	}
+}
@@ -7,6 +7,7 @@ package repo
 
 import (
 	"errors"
+	"time"
 )
 
 // Repo abstracts a version control system (VCS) such as git, mercurial or
@@ -37,17 +38,94 @@ type Repo interface {
 	Cleanup() error
 }
 
-// New creates a new repository. vcsType corresponds to the VCS type
-// (currently, only 'git' is supported) whereas clonePath corresponds to the
-// absolute path to/for the repository on disk and cloneURL is the URL used
-// for cloning/updating the repository.
+// Git backends, ie the implementations of git repositories.
+const (
+	// GitBackendLibgit2 uses libgit2, through git2go. It is not available
+	// when building with the nolibgit2 tag.
+	GitBackendLibgit2 = "libgit2"
+
+	// GitBackendGoGit uses go-git, a pure Go git implementation.
+	GitBackendGoGit = "go-git"
+
+	// GitBackendCLI uses the git command, which must be installed.
+	GitBackendCLI = "cli"
+)
+
+// Options are the options of the repositories, which only apply to some of
+// the VCS types.
+type Options struct {
+	// GitBackend is the implementation of git repositories, one of the
+	// GitBackend constants. It defaults to GitBackendLibgit2, or to
+	// GitBackendGoGit when building with the nolibgit2 tag.
+	GitBackend string
+
+	// Timeout is the maximum duration of the commands run to clone or update
+	// repositories, 0 for no limit. It only applies to the VCS types and git
+	// backends that use commands, ie hg, svn and the git CLI backend.
+	Timeout time.Duration
+
+	// CloneDepth is the number of commits to fetch when cloning and updating
+	// git repositories, 0 to fetch their whole history. It is not supported
+	// by the libgit2 git backend.
+	CloneDepth int
+
+	// SingleBranch specifies whether only the default branch of git
+	// repositories shall be fetched. It is not supported by the libgit2 git
+	// backend.
+	SingleBranch bool
+
+	// CloneFilter is the partial clone filter of git repositories, for
+	// instance "blob:none" to fetch file contents only when they are checked
+	// out. It is only supported by the cli git backend.
+	CloneFilter string
+
+	// SVNPath is the path to check out from subversion repositories,
+	// relative to their clone URL. It defaults to "trunk". "." checks out
+	// the clone URL itself.
+	SVNPath string
+}
+
+// New creates a new repository. vcsType corresponds to the VCS type ('git',
+// 'hg' or 'svn') whereas clonePath corresponds to the absolute path to/for
+// the repository on disk and cloneURL is the URL used for cloning/updating
+// the repository.
 func New(vcsType, clonePath string, cloneURL string) (Repo, error) {
+	return NewWithOptions(vcsType, clonePath, cloneURL, Options{})
+}
+
+// NewWithOptions is like New but it also takes the options of the
+// repository.
+func NewWithOptions(vcsType, clonePath string, cloneURL string, opts Options) (Repo, error) {
 	var newRepo Repo
 	var err error
 
 	switch vcsType {
 	case "git":
-		newRepo, err = newGitRepo(clonePath, cloneURL)
+		backend := opts.GitBackend
+		if backend == "" {
+			backend = defaultGitBackend
+		}
+
+		switch backend {
+		case GitBackendLibgit2:
+			if opts.CloneDepth > 0 || opts.SingleBranch || opts.CloneFilter != "" {
+				return nil, errors.New("shallow, single branch and partial clones are not supported by the libgit2 git backend")
+			}
+			newRepo, err = newGitRepo(clonePath, cloneURL)
+		case GitBackendGoGit:
+			if opts.CloneFilter != "" {
+				return nil, errors.New("partial clones are not supported by the go-git git backend")
+			}
+			newRepo, err = newGoGitRepo(clonePath, cloneURL, opts.CloneDepth, opts.SingleBranch)
+		case GitBackendCLI:
+			newRepo, err = newGitCLIRepo(clonePath, cloneURL, opts)
+		default:
+			return nil, errors.New("unsupported git backend: " + backend)
+		}
+	case "hg":
+		newRepo, err = newHgRepo(clonePath, cloneURL, opts.Timeout)
+	case "svn":
+		newRepo, err = newSVNRepo(clonePath, cloneURL, opts.SVNPath, opts.Timeout)
 	default:
 		return nil, errors.New("unsupported vcs repository type: " + vcsType)
 	}
@@ -0,0 +1,102 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultSVNPath is the path checked out from subversion repositories when
+// none is configured.
+const defaultSVNPath = "trunk"
+
+// svnRepo implements the Repo interface.
+type svnRepo struct {
+	absPath string
+	url     string
+
+	// path is the path checked out, relative to url.
+	path string
+
+	// timeout is the maximum duration of the svn commands, 0 for no limit.
+	timeout time.Duration
+}
+
+// newSVNRepo creates a new svnRepo. svnRepo implements the Repo interface
+// for a subversion repository, using the svn command. path is the path to
+// check out relative to url, "trunk" if empty, or "." to check out url itself.
+func newSVNRepo(absPath string, url string, path string, timeout time.Duration) (*svnRepo, error) {
+	if _, err := exec.LookPath("svn"); err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		path = defaultSVNPath
+	}
+
+	return &svnRepo{absPath: absPath, url: url, path: path, timeout: timeout}, nil
+}
+
+// AbsPath implements the AbsPath() method of the Repo interface.
+func (sr svnRepo) AbsPath() string {
+	return sr.absPath
+}
+
+// SetAbsPath implements the SetAbsPath() method of the Repo interface.
+func (sr *svnRepo) SetAbsPath(path string) {
+	sr.absPath = path
+}
+
+// URL implements the URL() method of the Repo interface.
+func (sr svnRepo) URL() string {
+	return sr.url
+}
+
+// checkoutURL returns the URL of the path to check out. The clone URL is
+// used as is if it already points to that path.
+func (sr svnRepo) checkoutURL() string {
+	url := strings.TrimRight(sr.url, "/")
+	path := strings.Trim(sr.path, "/")
+	if path == "." || strings.HasSuffix(url, "/"+path) {
+		return url
+	}
+	return url + "/" + path
+}
+
+// Clone implements the Clone() method of the Repo interface.
+// It checks out the configured path of the repository.
+func (sr svnRepo) Clone() error {
+	if err := os.MkdirAll(filepath.Dir(sr.absPath), 0755); err != nil {
+		return err
+	}
+
+	return sr.run("checkout", "--", sr.checkoutURL(), sr.absPath)
+}
+
+// Update implements the Update() method of the Repo interface.
+// It reverts local changes and updates the working copy to the latest
+// revision.
+func (sr svnRepo) Update() error {
+	if err := sr.run("revert", "--recursive", "--", sr.absPath); err != nil {
+		return err
+	}
+
+	return sr.run("update", "--", sr.absPath)
+}
+
+// Cleanup implements the Cleanup() method of the Repo interface.
+func (sr svnRepo) Cleanup() error {
+	return nil
+}
+
+// run runs the svn command with the given arguments, non-interactively and
+// with untranslated messages.
+func (sr svnRepo) run(args ...string) error {
+	return runCmd(sr.timeout, "", []string{"LC_ALL=C"}, "svn", append([]string{"--non-interactive", "--quiet"}, args...)...)
+}
@@ -0,0 +1,105 @@
+// Copyright 2014-2015 The DevMine authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// svn runs the given svn command, failing the test if it does not succeed.
+func svn(t *testing.T, name string, args ...string) {
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s %v: %v (%s)", name, args, err, out)
+	}
+}
+
+func TestSVNRepo(t *testing.T) {
+	if _, err := exec.LookPath("svnadmin"); err != nil {
+		t.Skip("svnadmin is not installed")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "crawld-svn-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	server := filepath.Join(tmpDir, "server")
+	svn(t, "svnadmin", "create", server)
+	url := "file://" + server
+
+	// the standard layout, with a file in trunk
+	layout := filepath.Join(tmpDir, "layout")
+	for _, dir := range []string{"trunk", "branches", "tags"} {
+		if err := os.MkdirAll(filepath.Join(layout, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(layout, "trunk", "README"), []byte("crawld"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	svn(t, "svn", "import", "--non-interactive", "-m", "initial import", layout, url)
+
+	r, err := New("svn", filepath.Join(tmpDir, "clones", "src"), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Cleanup()
+
+	if err := r.Clone(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(r.AbsPath(), "README")); err != nil {
+		t.Errorf("Clone: trunk not checked out: %v", err)
+	}
+
+	// commit a new file from another working copy
+	wc := filepath.Join(tmpDir, "wc")
+	svn(t, "svn", "checkout", "--non-interactive", url+"/trunk", wc)
+	if err := ioutil.WriteFile(filepath.Join(wc, "LICENSE"), []byte("BSD"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	svn(t, "svn", "add", "--non-interactive", filepath.Join(wc, "LICENSE"))
+	svn(t, "svn", "commit", "--non-interactive", "-m", "add license", wc)
+
+	// local changes are discarded
+	if err := ioutil.WriteFile(filepath.Join(r.AbsPath(), "README"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(r.AbsPath(), "LICENSE")); err != nil {
+		t.Errorf("Update: LICENSE not checked out: %v", err)
+	}
+	if bs, _ := ioutil.ReadFile(filepath.Join(r.AbsPath(), "README")); string(bs) != "crawld" {
+		t.Errorf("Update: expected README to be reverted, found %q", bs)
+	}
+}
+
+func TestSVNRepoCheckoutURL(t *testing.T) {
+	tests := []struct {
+		url, path, expected string
+	}{
+		{"svn://example.com/crawld/", "trunk", "svn://example.com/crawld/trunk"},
+		{"svn://example.com/crawld/trunk", "trunk", "svn://example.com/crawld/trunk"},
+		{"svn://example.com/crawld", "branches/stable", "svn://example.com/crawld/branches/stable"},
+		{"svn://example.com/crawld", ".", "svn://example.com/crawld"},
+	}
+
+	for _, tt := range tests {
+		sr := svnRepo{url: tt.url, path: tt.path}
+		if u := sr.checkoutURL(); u != tt.expected {
+			t.Errorf("checkoutURL(%q, %q): expected %q, found %q", tt.url, tt.path, tt.expected, u)
+		}
+	}
+}